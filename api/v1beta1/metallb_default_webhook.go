@@ -0,0 +1,47 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:verbs=create;update,path=/mutate-metallb-io-v1beta1-metallb,mutating=true,failurePolicy=fail,groups=metallb.io,resources=metallbs,versions=v1beta1,name=metallbdefaultingwebhook.metallb.io,sideEffects=None,admissionReviewVersions=v1
+
+var _ admission.Defaulter[*MetalLB] = &MetalLB{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for MetalLB.
+// It materializes the defaults that do not depend on the platform into the spec.
+// The BGP backend and the frr-k8s namespace are left unset, so that they keep
+// following the platform and the operator configuration, and are reported in
+// the effectiveSpec of the status instead.
+func (metallb *MetalLB) Default(_ context.Context, obj *MetalLB) error {
+	obj.SetDefaults()
+	return nil
+}
+
+// SetDefaults sets the unset fields of the spec to their default values.
+func (metallb *MetalLB) SetDefaults() {
+	if metallb.Spec.LogLevel == "" {
+		metallb.Spec.LogLevel = LogLevelInfo
+	}
+	if metallb.Spec.ApplyMode == "" {
+		metallb.Spec.ApplyMode = ApplyModeApply
+	}
+}
@@ -0,0 +1,63 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     MetalLBSpec
+		expected MetalLBSpec
+	}{
+		{
+			name: "empty spec",
+			spec: MetalLBSpec{},
+			expected: MetalLBSpec{
+				LogLevel:  LogLevelInfo,
+				ApplyMode: ApplyModeApply,
+			},
+		},
+		{
+			name: "user provided values",
+			spec: MetalLBSpec{
+				LogLevel:   LogLevelDebug,
+				BGPBackend: NativeMode,
+				ApplyMode:  ApplyModePreview,
+			},
+			expected: MetalLBSpec{
+				LogLevel:   LogLevelDebug,
+				BGPBackend: NativeMode,
+				ApplyMode:  ApplyModePreview,
+			},
+		},
+		{
+			name: "external frr-k8s namespace not materialized",
+			spec: MetalLBSpec{
+				BGPBackend:   FRRK8sExternalMode,
+				FRRK8SConfig: &FRRK8SConfig{AlwaysBlock: []string{"10.0.0.0/16"}},
+			},
+			expected: MetalLBSpec{
+				LogLevel:     LogLevelInfo,
+				BGPBackend:   FRRK8sExternalMode,
+				ApplyMode:    ApplyModeApply,
+				FRRK8SConfig: &FRRK8SConfig{AlwaysBlock: []string{"10.0.0.0/16"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metallb := &MetalLB{Spec: test.spec}
+			if err := metallb.Default(context.Background(), metallb); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, metallb.Spec); diff != "" {
+				t.Fatalf("unexpected defaulted spec: %s", diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -45,15 +46,57 @@ const (
 
 type BGPType string
 
+// ApplyMode defines how the operator handles the changes to the operands.
+type ApplyMode string
+
+const (
+	// ApplyModeApply applies the changes to the operands.
+	ApplyModeApply ApplyMode = "Apply"
+	// ApplyModePreview reports the changes to the operands in the status
+	// without applying them.
+	ApplyModePreview ApplyMode = "Preview"
+)
+
+type MonitorType string
+
+const (
+	// ServiceMonitorType deploys ServiceMonitors to scrape the metrics.
+	ServiceMonitorType MonitorType = "ServiceMonitor"
+	// PodMonitorType deploys PodMonitors to scrape the metrics.
+	PodMonitorType MonitorType = "PodMonitor"
+	// NoMonitorType deploys no monitors.
+	NoMonitorType MonitorType = "None"
+)
+
+type OverridePatchType string
+
+const (
+	// StrategicMergePatch applies the patch as a strategic merge patch.
+	StrategicMergePatch OverridePatchType = "StrategicMerge"
+	// JSONPatch applies the patch as a JSON patch (RFC 6902).
+	JSONPatch OverridePatchType = "JSON6902"
+)
+
 // MetalLBSpec defines the desired state of MetalLB
 type MetalLBSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// image sets the metallb image.
-	// Deprecated: The image property has no effect and will be removed in a future version.
+	// Deprecated: The image property has no effect and will be removed in a future version,
+	// use imageRegistry instead.
 	MetalLBImage string `json:"image,omitempty"`
 
+	// The registry the images of the operands are pulled from, replacing the
+	// registry of the images the operator is configured with. It may include
+	// a path, for example mirror.example.com:5000/metallb.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// The secrets used to pull the images of the operands.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// node selector applied to MetalLB speaker daemonset.
 	// +optional
 	SpeakerNodeSelector map[string]string `json:"nodeSelector,omitempty"`
@@ -100,6 +143,43 @@ type MetalLBSpec struct {
 
 	// The specific frr-k8s configuration
 	FRRK8SConfig *FRRK8SConfig `json:"frrk8sConfig,omitempty"`
+
+	// The interfaces the speaker must not announce L2 services from.
+	// +optional
+	ExcludeInterfaces *ExcludeInterfaces `json:"excludeInterfaces,omitempty"`
+
+	// When set, the speaker announces services from the nodes labeled with
+	// node.kubernetes.io/exclude-from-external-load-balancers.
+	// +optional
+	IgnoreExcludeLB bool `json:"ignoreExcludeLB,omitempty"`
+
+	// The configuration of the memberlist cluster the speakers form to detect
+	// the unavailable nodes faster.
+	// +optional
+	Memberlist *MemberlistConfig `json:"memberlist,omitempty"`
+
+	// The time to wait for the BGP configuration changes to settle before
+	// reloading FRR, applied to both the speaker and frr-k8s. Must be a whole
+	// number of milliseconds. When not set, the components' default is used.
+	// +optional
+	BGPDebounceTimeout *metav1.Duration `json:"bgpDebounceTimeout,omitempty"`
+
+	// The configuration of the monitoring of MetalLB.
+	// +optional
+	Monitoring *MonitoringConfig `json:"monitoring,omitempty"`
+
+	// Define how the changes to the MetalLB resource are handled. With Apply
+	// the operands are updated, with Preview the changes that would be applied
+	// are reported in the status instead. (default: Apply)
+	// +optional
+	// +kubebuilder:validation:Enum=Apply;Preview
+	ApplyMode ApplyMode `json:"applyMode,omitempty"`
+
+	// Patches applied to the objects rendered by the operator, to set the
+	// fields not exposed by the MetalLB resource. The overrides are applied
+	// in order, after all the other settings.
+	// +optional
+	Overrides []ObjectOverride `json:"overrides,omitempty"`
 }
 
 type FRRK8SConfig struct {
@@ -112,9 +192,191 @@ type FRRK8SConfig struct {
 	// Only valid when frr-k8s runs in external mode.
 	// +optional
 	SecretPassthrough bool `json:"secretPassthrough,omitempty"`
+
+	// The fields below configure the frr-k8s daemonset deployed by the
+	// operator. When set, they override the values mirrored from the speaker.
+
+	// The node selector of the frr-k8s pods.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// The tolerations of the frr-k8s pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// The scheduling constraints of the frr-k8s pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// The priority class of the frr-k8s pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// The resource requirements of the frr-k8s controller container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Annotations to be added to the frr-k8s pods.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ExcludeInterfaces struct {
+	// When set to false, L2 services are announced from all the interfaces,
+	// including the virtual ones created by the container runtimes and CNIs.
+	// (default: true)
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// A list of regular expressions matching the names of the interfaces to
+	// exclude in addition to the default ones.
+	// +optional
+	Interfaces []string `json:"interfaces,omitempty"`
+}
+
+type MemberlistConfig struct {
+	// When set to false, the speakers don't form a memberlist cluster and
+	// must run on all the nodes. (default: true)
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// The port the speakers bind to for the memberlist traffic. When not set,
+	// the port the operator is configured with is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	BindPort *int32 `json:"bindPort,omitempty"`
+
+	// The name of the Secret holding the key the memberlist traffic is
+	// encrypted with. The Secret is generated by the operator.
+	// (default: metallb-memberlist)
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// How often the operator generates a new memberlist key. The speakers are
+	// restarted via a rolling update to pick the new key up, and a new key is
+	// not generated while a previous rollout is in progress. The speakers take
+	// a single key, so during the rollout the ones using the new key can't
+	// join the memberlist cluster of the ones using the old key: both sides see
+	// the nodes of the other one as dead and the same L2 services can be
+	// announced from two nodes until the rollout completes. When not set, the
+	// key is never rotated.
+	// +optional
+	KeyRotationInterval *metav1.Duration `json:"keyRotationInterval,omitempty"`
+}
+
+type MonitoringConfig struct {
+	// The kind of the monitors deployed to scrape the metrics of MetalLB,
+	// one of ServiceMonitor, PodMonitor or None. The frr-k8s metrics are
+	// scraped only with ServiceMonitors. When not set, the operator's
+	// DEPLOY_SERVICEMONITORS and DEPLOY_PODMONITORS settings are used.
+	// +optional
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor;None
+	Type MonitorType `json:"type,omitempty"`
+
+	// How often the metrics are scraped. Must be a whole number of seconds.
+	// When not set, the Prometheus default is used.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Additional labels set on the monitors, for example to match the
+	// monitor selectors of Prometheus.
+	// +optional
+	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
+
+	// Relabelings applied to the scraped metrics before ingestion.
+	// +optional
+	MetricRelabelings []RelabelConfig `json:"metricRelabelings,omitempty"`
+
+	// The configuration of the alerts deployed for MetalLB.
+	// +optional
+	Alerts *AlertsConfig `json:"alerts,omitempty"`
+}
+
+// RelabelConfig is a Prometheus relabeling rule.
+type RelabelConfig struct {
+	// The labels whose values are concatenated and matched against the regex.
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// The separator placed between the concatenated source label values.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+
+	// The label the result is written to, for the replace action.
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// The regular expression the concatenated values are matched against.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// The modulus of the hash of the source label values, for the hashmod
+	// action.
+	// +optional
+	Modulus uint64 `json:"modulus,omitempty"`
+
+	// The replacement value, for the replace action.
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+
+	// The action to perform. (default: replace)
+	// +optional
+	// +kubebuilder:validation:Enum=replace;Replace;keep;Keep;drop;Drop;hashmod;HashMod;labelmap;LabelMap;labeldrop;LabelDrop;labelkeep;LabelKeep;lowercase;Lowercase;uppercase;Uppercase;keepequal;KeepEqual;dropequal;DropEqual
+	Action string `json:"action,omitempty"`
+}
+
+type AlertsConfig struct {
+	// When set to true, a PrometheusRule with the MetalLB alerts is deployed,
+	// together with one with the frr-k8s BGP and BFD session alerts when
+	// running in frr-k8s mode with ServiceMonitors. When not set, the operator's
+	// DEPLOY_PROMETHEUSRULES setting is used.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// The severity of the alerts, by alert name, overriding the default one.
+	// The supported alerts are MetalLBStaleConfig, MetalLBConfigNotLoaded,
+	// MetalLBAddressPoolExhausted, MetalLBBGPSessionDown, FRRK8sBGPSessionDown
+	// and FRRK8sBFDSessionDown.
+	// +optional
+	Severities map[string]string `json:"severities,omitempty"`
+
+	// Additional labels set on the PrometheusRules, for example to match the
+	// rule selector of Prometheus.
+	// +optional
+	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
+}
+
+type ObjectOverride struct {
+	// The kind of the rendered object to patch, for example DaemonSet.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// The name of the rendered object to patch, for example speaker.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The type of the patch. (default: StrategicMerge)
+	// +optional
+	// +kubebuilder:validation:Enum=StrategicMerge;JSON6902
+	Type OverridePatchType `json:"type,omitempty"`
+
+	// The patch to apply, in YAML or JSON. The patch can't change the
+	// selector of the controller deployment or of the daemonsets, which is
+	// immutable.
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
 }
 
 type Config struct {
+	// Number of replicas. Only applies to the controller, and only a single
+	// replica is supported as the MetalLB controller does not implement leader
+	// election. (default: 1)
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Define priority class name
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
@@ -135,6 +397,18 @@ type Config struct {
 	// via MetalLB Operator
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Resource Requirements of single containers, keyed by container name.
+	// They take precedence over Resources. For the speaker, the containers of
+	// the frr-k8s daemonset are matched too.
+	// +optional
+	ContainerResources map[string]corev1.ResourceRequirements `json:"containerResources,omitempty"`
+
+	// The update strategy of the speaker daemonset, mirrored to the frr-k8s
+	// daemonset when frr-k8s is deployed by the operator. Only applies to the
+	// speaker.
+	// +optional
+	UpdateStrategy *appsv1.DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
 }
 
 // MetalLBStatus defines the observed state of MetalLB
@@ -142,8 +416,82 @@ type MetalLBStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Conditions show the current state of the MetalLB Operator
+	// Conditions show the current state of the MetalLB Operator, together with
+	// the readiness of each of the components it deploys.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ControllerReadyReplicas is the number of ready replicas of the MetalLB
+	// controller.
+	// +optional
+	ControllerReadyReplicas int32 `json:"controllerReadyReplicas,omitempty"`
+
+	// Images lists the images of the containers of the operands.
+	// +optional
+	Images []OperandImage `json:"images,omitempty"`
+
+	// ObservedGeneration is the most recent generation of the MetalLB resource
+	// processed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// PendingChanges lists the changes to the operands that would be applied
+	// when the applyMode is Preview.
+	// +optional
+	PendingChanges []OperandChange `json:"pendingChanges,omitempty"`
+
+	// EffectiveSpec reports the values in effect for the fields of the spec
+	// whose defaults depend on the platform and on the operator configuration.
+	// +optional
+	EffectiveSpec *EffectiveSpec `json:"effectiveSpec,omitempty"`
+}
+
+// EffectiveSpec describes the values the operator applies for the fields left
+// unset in the spec.
+type EffectiveSpec struct {
+	// BGPBackend is the BGP implementation deployed with MetalLB.
+	BGPBackend BGPType `json:"bgpBackend"`
+
+	// FRRK8sNamespace is the namespace where frr-k8s is expected to run, set
+	// when frr-k8s is deployed externally to the operator.
+	// +optional
+	FRRK8sNamespace string `json:"frrk8sNamespace,omitempty"`
+}
+
+// OperandImage describes the image of a container of an operand.
+type OperandImage struct {
+	// Workload running the container, in the Kind/name form.
+	Workload string `json:"workload"`
+
+	// Name of the container.
+	Container string `json:"container"`
+
+	// Image reference the container is configured with.
+	Image string `json:"image"`
+
+	// ImageIDs lists the resolved image references, including the digest,
+	// reported by the running pods of the workload.
+	// +optional
+	ImageIDs []string `json:"imageIDs,omitempty"`
+}
+
+// OperandChange describes a change to an object deployed by the operator.
+type OperandChange struct {
+	// Kind of the changed object.
+	Kind string `json:"kind"`
+
+	// Namespace of the changed object, empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the changed object.
+	Name string `json:"name"`
+
+	// Action performed on the object, one of Create, Update or Delete.
+	Action string `json:"action"`
+
+	// Fields lists the paths of the fields that would be changed by an Update.
+	// +optional
+	Fields []string `json:"fields,omitempty"`
 }
 
 // +kubebuilder:object:root=true
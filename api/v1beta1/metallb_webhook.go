@@ -21,19 +21,45 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var ExternalFRRK8sNamespace string
 
+// imageRegistryRegexp matches a registry host, with an optional port and path.
+var imageRegistryRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?(/[a-z0-9]+([._-]+[a-z0-9]+)*)*$`)
+
+// alertNames are the alerts whose severity can be configured.
+var alertNames = []string{
+	"MetalLBStaleConfig",
+	"MetalLBConfigNotLoaded",
+	"MetalLBAddressPoolExhausted",
+	"MetalLBBGPSessionDown",
+	"FRRK8sBGPSessionDown",
+	"FRRK8sBFDSessionDown",
+}
+
+// OverridesValidator checks that the overrides of the MetalLB resource apply
+// to the objects the operator renders for it. It is set by the operator, which
+// owns the charts.
+var OverridesValidator func(*MetalLB) error
+
 func (metallb *MetalLB) SetupWebhookWithManager(mgr ctrl.Manager, externalFRRK8sNamespace string) error {
 	ExternalFRRK8sNamespace = externalFRRK8sNamespace
 	return ctrl.NewWebhookManagedBy(mgr, metallb).
 		WithValidator(metallb).
+		WithDefaulter(metallb).
 		Complete()
 }
 
@@ -46,7 +72,10 @@ func (metallb *MetalLB) ValidateCreate(_ context.Context, obj *MetalLB) (admissi
 	if err := obj.Validate(); err != nil {
 		return admission.Warnings{}, err
 	}
-	return admission.Warnings{}, nil
+	if err := validateOverrideTargets(obj); err != nil {
+		return admission.Warnings{}, err
+	}
+	return specWarnings(obj), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for MetalLB.
@@ -54,7 +83,10 @@ func (metallb *MetalLB) ValidateUpdate(_ context.Context, _ *MetalLB, obj *Metal
 	if err := obj.Validate(); err != nil {
 		return admission.Warnings{}, err
 	}
-	return admission.Warnings{}, nil
+	if err := validateOverrideTargets(obj); err != nil {
+		return admission.Warnings{}, err
+	}
+	return specWarnings(obj), nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for MetalLB.
@@ -117,6 +149,227 @@ func (metallb *MetalLB) Validate() error {
 	if err := validateFRRK8sConfig(metallb.Spec); err != nil {
 		return err
 	}
+	if err := validateExcludeInterfaces(metallb.Spec.ExcludeInterfaces); err != nil {
+		return err
+	}
+	if err := validateBGPDebounceTimeout(metallb.Spec.BGPDebounceTimeout); err != nil {
+		return err
+	}
+	if err := validateMemberlist(metallb.Spec); err != nil {
+		return err
+	}
+	if metallb.Spec.SpeakerConfig != nil && metallb.Spec.SpeakerConfig.Replicas != nil {
+		return errors.New("replicas can't be set on the speaker, which runs on every node")
+	}
+	// The MetalLB controller has no leader election, and multiple active
+	// replicas would allocate the service IPs independently of each other.
+	if metallb.Spec.ControllerConfig != nil && metallb.Spec.ControllerConfig.Replicas != nil && *metallb.Spec.ControllerConfig.Replicas > 1 {
+		return errors.New("the controller supports a single replica, as it does not implement leader election")
+	}
+	if err := validateContainerResources(metallb.Spec.ControllerConfig, controllerContainers); err != nil {
+		return err
+	}
+	if err := validateContainerResources(metallb.Spec.SpeakerConfig, speakerContainers); err != nil {
+		return err
+	}
+	if err := validateMonitoring(metallb.Spec.Monitoring); err != nil {
+		return err
+	}
+	if err := validateImages(metallb.Spec); err != nil {
+		return err
+	}
+	if err := validateOverrides(metallb.Spec.Overrides); err != nil {
+		return err
+	}
+	if metallb.Spec.ControllerConfig != nil && metallb.Spec.ControllerConfig.UpdateStrategy != nil {
+		return errors.New("updateStrategy can only be set on the speaker")
+	}
+	if metallb.Spec.SpeakerConfig != nil {
+		if err := validateUpdateStrategy(metallb.Spec.SpeakerConfig.UpdateStrategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateBGPDebounceTimeout(timeout *metav1.Duration) error {
+	if timeout == nil {
+		return nil
+	}
+	if timeout.Duration < time.Millisecond {
+		return fmt.Errorf("bgpDebounceTimeout must be at least 1ms, got %s", timeout.Duration)
+	}
+	if timeout.Duration%time.Millisecond != 0 {
+		return fmt.Errorf("bgpDebounceTimeout must be a whole number of milliseconds, got %s", timeout.Duration)
+	}
+	return nil
+}
+
+// The containers of the pods deployed by the operator. The speaker ones
+// include the frr-k8s daemonset containers.
+var (
+	controllerContainers = []string{"controller"}
+	speakerContainers    = []string{"speaker", "frr", "reloader", "frr-metrics", "controller", "frr-status"}
+)
+
+func validateContainerResources(config *Config, containers []string) error {
+	if config == nil {
+		return nil
+	}
+	for name := range config.ContainerResources {
+		if !slices.Contains(containers, name) {
+			return fmt.Errorf("invalid containerResources entry %q, must be one of %s", name, strings.Join(containers, ", "))
+		}
+	}
+	return nil
+}
+
+func validateUpdateStrategy(strategy *appsv1.DaemonSetUpdateStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+	switch strategy.Type {
+	case "", appsv1.RollingUpdateDaemonSetStrategyType:
+	case appsv1.OnDeleteDaemonSetStrategyType:
+		if strategy.RollingUpdate != nil {
+			return errors.New("updateStrategy rollingUpdate can't be set with the OnDelete type")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid updateStrategy type %q, must be one of %s, %s", strategy.Type, appsv1.RollingUpdateDaemonSetStrategyType, appsv1.OnDeleteDaemonSetStrategyType)
+	}
+	if strategy.RollingUpdate == nil {
+		return nil
+	}
+	// The pods use the host network, so a surge pod can't be scheduled on a
+	// node where the old pod is still binding the same ports.
+	if strategy.RollingUpdate.MaxSurge != nil {
+		surge, err := intstr.GetScaledValueFromIntOrPercent(strategy.RollingUpdate.MaxSurge, 100, true)
+		if err != nil {
+			return fmt.Errorf("invalid updateStrategy maxSurge: %w", err)
+		}
+		if surge != 0 {
+			return errors.New("updateStrategy maxSurge is not supported, as the speaker and frr-k8s pods use host ports")
+		}
+	}
+	if strategy.RollingUpdate.MaxUnavailable != nil {
+		unavailable, err := intstr.GetScaledValueFromIntOrPercent(strategy.RollingUpdate.MaxUnavailable, 100, true)
+		if err != nil {
+			return fmt.Errorf("invalid updateStrategy maxUnavailable: %w", err)
+		}
+		if unavailable <= 0 {
+			return fmt.Errorf("updateStrategy maxUnavailable must be greater than zero, got %s", strategy.RollingUpdate.MaxUnavailable.String())
+		}
+	}
+	return nil
+}
+
+// minMemberlistKeyRotationInterval avoids restarting the speakers too often.
+const minMemberlistKeyRotationInterval = time.Hour
+
+func validateMemberlist(spec MetalLBSpec) error {
+	config := spec.Memberlist
+	if config == nil {
+		return nil
+	}
+	if config.Enabled != nil && !*config.Enabled && len(spec.SpeakerNodeSelector) > 0 {
+		return errors.New("speakerNodeSelector must be empty when memberlist is disabled, as the speakers must run on all the nodes")
+	}
+	if config.SecretName != "" {
+		if errs := validation.IsDNS1123Subdomain(config.SecretName); len(errs) > 0 {
+			return fmt.Errorf("invalid memberlist secretName %q: %s", config.SecretName, strings.Join(errs, ", "))
+		}
+	}
+	if config.KeyRotationInterval != nil && config.KeyRotationInterval.Duration < minMemberlistKeyRotationInterval {
+		return fmt.Errorf("memberlist keyRotationInterval must be at least %s, got %s", minMemberlistKeyRotationInterval, config.KeyRotationInterval.Duration)
+	}
+	return nil
+}
+
+func validateMonitoring(config *MonitoringConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Type != "" && config.Type != ServiceMonitorType && config.Type != PodMonitorType && config.Type != NoMonitorType {
+		return fmt.Errorf("monitoring: invalid type %s, must be one of %s, %s, %s", config.Type, ServiceMonitorType, PodMonitorType, NoMonitorType)
+	}
+	if config.Interval != nil && (config.Interval.Duration < time.Second || config.Interval.Duration%time.Second != 0) {
+		return fmt.Errorf("monitoring: invalid interval %s, must be a whole number of seconds", config.Interval.Duration)
+	}
+	if err := validateLabels(config.AdditionalLabels); err != nil {
+		return fmt.Errorf("monitoring: %w", err)
+	}
+	for _, r := range config.MetricRelabelings {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("monitoring: invalid metricRelabelings regex %s: %w", r.Regex, err)
+		}
+	}
+	if config.Alerts == nil {
+		return nil
+	}
+	for name, severity := range config.Alerts.Severities {
+		if !slices.Contains(alertNames, name) {
+			return fmt.Errorf("alerts: unknown alert %s, must be one of %s", name, strings.Join(alertNames, ", "))
+		}
+		if errs := validation.IsValidLabelValue(severity); severity == "" || len(errs) > 0 {
+			return fmt.Errorf("alerts: invalid severity %q for %s", severity, name)
+		}
+	}
+	if err := validateLabels(config.Alerts.AdditionalLabels); err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid label %q: %s", k, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q for label %s: %s", v, k, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+func validateImages(spec MetalLBSpec) error {
+	if spec.ImageRegistry != "" && !imageRegistryRegexp.MatchString(spec.ImageRegistry) {
+		return fmt.Errorf("invalid imageRegistry %s, must be a registry host with an optional port and path", spec.ImageRegistry)
+	}
+	for _, s := range spec.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(s.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid imagePullSecrets name %q: %s", s.Name, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// specWarnings returns the warnings about the deprecated or disruptive fields
+// set in the resource.
+func specWarnings(metallb *MetalLB) admission.Warnings {
+	res := admission.Warnings{}
+	if metallb.Spec.MetalLBImage != "" {
+		res = append(res, "spec.image is deprecated and has no effect, use spec.imageRegistry instead")
+	}
+	if metallb.Spec.Memberlist != nil && metallb.Spec.Memberlist.KeyRotationInterval != nil {
+		res = append(res, "spec.memberlist.keyRotationInterval: the L2 announcements may be duplicated while the speakers are rolled out with a new memberlist key")
+	}
+	return res
+}
+
+func validateExcludeInterfaces(config *ExcludeInterfaces) error {
+	if config == nil {
+		return nil
+	}
+	if config.Enabled != nil && !*config.Enabled && len(config.Interfaces) > 0 {
+		return errors.New("excludeInterfaces: interfaces can't be set when excluding interfaces is disabled")
+	}
+	for _, i := range config.Interfaces {
+		if _, err := regexp.Compile(i); err != nil {
+			return fmt.Errorf("excludeInterfaces: invalid regular expression %s: %w", i, err)
+		}
+	}
 	return nil
 }
 
@@ -130,6 +383,9 @@ func validateFRRK8sConfig(spec MetalLBSpec) error {
 	if config == nil {
 		return nil
 	}
+	if spec.BGPBackend == FRRK8sExternalMode && hasFRRK8sDaemonConfig(config) {
+		return errors.New("bgp backend: frrk8s external, the frr-k8s daemonset configuration can't be set")
+	}
 	for _, cidr := range config.AlwaysBlock {
 		_, _, err := net.ParseCIDR(strings.TrimSpace(cidr))
 		if err != nil {
@@ -138,3 +394,34 @@ func validateFRRK8sConfig(spec MetalLBSpec) error {
 	}
 	return nil
 }
+
+// hasFRRK8sDaemonConfig tells if any of the settings of the frr-k8s daemonset
+// deployed by the operator is set.
+func hasFRRK8sDaemonConfig(config *FRRK8SConfig) bool {
+	return len(config.NodeSelector) > 0 || len(config.Tolerations) > 0 || config.Affinity != nil ||
+		config.PriorityClassName != "" || config.Resources != nil || len(config.Annotations) > 0
+}
+
+func validateOverrides(overrides []ObjectOverride) error {
+	for i, o := range overrides {
+		if o.Kind == "" || o.Name == "" {
+			return fmt.Errorf("override %d: kind and name must be set", i)
+		}
+		if o.Type != "" && o.Type != StrategicMergePatch && o.Type != JSONPatch {
+			return fmt.Errorf("override %d: invalid patch type %s, must be one of %s, %s", i, o.Type, StrategicMergePatch, JSONPatch)
+		}
+		if strings.TrimSpace(o.Patch) == "" {
+			return fmt.Errorf("override %d: patch must be set", i)
+		}
+	}
+	return nil
+}
+
+// validateOverrideTargets runs the OverridesValidator, if any, when the
+// resource has overrides.
+func validateOverrideTargets(metallb *MetalLB) error {
+	if len(metallb.Spec.Overrides) == 0 || OverridesValidator == nil {
+		return nil
+	}
+	return OverridesValidator(metallb)
+}
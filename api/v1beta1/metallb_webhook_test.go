@@ -1,8 +1,16 @@
 package v1beta1
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestValidateFRRK8sConfig(t *testing.T) {
@@ -90,4 +98,549 @@ func TestValidateFRRK8sConfig(t *testing.T) {
 			t.Errorf("Expected no error, got: %v", err)
 		}
 	})
+
+	t.Run("Daemon config", func(t *testing.T) {
+		err := validateFRRK8sConfig(MetalLBSpec{
+			BGPBackend: FRRK8sMode,
+			FRRK8SConfig: &FRRK8SConfig{
+				NodeSelector:      map[string]string{"foo": "bar"},
+				PriorityClassName: "high-priority",
+			},
+		})
+
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("External, daemon config", func(t *testing.T) {
+		err := validateFRRK8sConfig(MetalLBSpec{
+			BGPBackend: FRRK8sExternalMode,
+			FRRK8SConfig: &FRRK8SConfig{
+				Namespace:    "foo",
+				NodeSelector: map[string]string{"foo": "bar"},
+			},
+		})
+
+		if err == nil {
+			t.Errorf("Expected error, got no error")
+		}
+	})
+}
+
+func TestValidateExcludeInterfaces(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name      string
+		config    *ExcludeInterfaces
+		shouldErr bool
+	}{
+		{
+			name:   "nil config",
+			config: nil,
+		},
+		{
+			name:   "valid interfaces",
+			config: &ExcludeInterfaces{Interfaces: []string{"^eth1$", "^storage.*"}},
+		},
+		{
+			name:      "invalid regular expression",
+			config:    &ExcludeInterfaces{Interfaces: []string{"^eth[1"}},
+			shouldErr: true,
+		},
+		{
+			name:      "interfaces with exclusion disabled",
+			config:    &ExcludeInterfaces{Enabled: &disabled, Interfaces: []string{"^eth1$"}},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateExcludeInterfaces(test.config)
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateBGPDebounceTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   *metav1.Duration
+		shouldErr bool
+	}{
+		{
+			name:    "not set",
+			timeout: nil,
+		},
+		{
+			name:    "valid",
+			timeout: &metav1.Duration{Duration: 5 * time.Second},
+		},
+		{
+			name:      "negative",
+			timeout:   &metav1.Duration{Duration: -time.Second},
+			shouldErr: true,
+		},
+		{
+			name:      "below a millisecond",
+			timeout:   &metav1.Duration{Duration: 500 * time.Microsecond},
+			shouldErr: true,
+		},
+		{
+			name:      "fraction of millisecond",
+			timeout:   &metav1.Duration{Duration: 1500 * time.Microsecond},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateBGPDebounceTimeout(test.timeout)
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateMemberlist(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name      string
+		spec      MetalLBSpec
+		shouldErr bool
+	}{
+		{
+			name: "not set",
+			spec: MetalLBSpec{},
+		},
+		{
+			name: "valid",
+			spec: MetalLBSpec{
+				Memberlist: &MemberlistConfig{
+					SecretName:          "my-memberlist",
+					KeyRotationInterval: &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+		},
+		{
+			name: "disabled",
+			spec: MetalLBSpec{
+				Memberlist: &MemberlistConfig{Enabled: &disabled},
+			},
+		},
+		{
+			name: "disabled with speaker node selector",
+			spec: MetalLBSpec{
+				Memberlist:          &MemberlistConfig{Enabled: &disabled},
+				SpeakerNodeSelector: map[string]string{"foo": "bar"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid secret name",
+			spec: MetalLBSpec{
+				Memberlist: &MemberlistConfig{SecretName: "Invalid_Name"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "rotation interval too short",
+			spec: MetalLBSpec{
+				Memberlist: &MemberlistConfig{KeyRotationInterval: &metav1.Duration{Duration: time.Minute}},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateMemberlist(test.spec)
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("key rotation warning", func(t *testing.T) {
+		metallb := &MetalLB{Spec: MetalLBSpec{Memberlist: &MemberlistConfig{KeyRotationInterval: &metav1.Duration{Duration: time.Hour}}}}
+		warnings, err := metallb.ValidateCreate(context.Background(), metallb)
+		if err != nil {
+			t.Errorf("Expected nil error, got: %v", err)
+		}
+		if len(warnings) != 1 {
+			t.Errorf("Expected a key rotation warning, got: %v", warnings)
+		}
+	})
+}
+
+func TestValidateReplicas(t *testing.T) {
+	replicas := int32(2)
+	one := int32(1)
+	t.Run("Controller single replica", func(t *testing.T) {
+		metallb := &MetalLB{Spec: MetalLBSpec{ControllerConfig: &Config{Replicas: &one}}}
+		if err := metallb.Validate(); err != nil {
+			t.Errorf("Expected nil error, got: %v", err)
+		}
+	})
+
+	t.Run("Controller multiple replicas", func(t *testing.T) {
+		metallb := &MetalLB{Spec: MetalLBSpec{ControllerConfig: &Config{Replicas: &replicas}}}
+		if err := metallb.Validate(); err == nil {
+			t.Errorf("Expected error, got no error")
+		}
+	})
+
+	t.Run("Speaker", func(t *testing.T) {
+		metallb := &MetalLB{Spec: MetalLBSpec{SpeakerConfig: &Config{Replicas: &replicas}}}
+		if err := metallb.Validate(); err == nil {
+			t.Errorf("Expected error, got no error")
+		}
+	})
+}
+
+func TestValidateUpdateStrategy(t *testing.T) {
+	one := intstr.FromInt32(1)
+	zero := intstr.FromInt32(0)
+	tenPercent := intstr.FromString("10%")
+	tests := []struct {
+		name      string
+		strategy  *appsv1.DaemonSetUpdateStrategy
+		shouldErr bool
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name: "rolling update",
+			strategy: &appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &tenPercent, MaxSurge: &zero},
+			},
+		},
+		{
+			name:     "on delete",
+			strategy: &appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+		},
+		{
+			name: "on delete with rolling update",
+			strategy: &appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.OnDeleteDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &one},
+			},
+			shouldErr: true,
+		},
+		{
+			name:      "invalid type",
+			strategy:  &appsv1.DaemonSetUpdateStrategy{Type: "Recreate"},
+			shouldErr: true,
+		},
+		{
+			name: "surge",
+			strategy: &appsv1.DaemonSetUpdateStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &zero, MaxSurge: &one},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "zero unavailable",
+			strategy: &appsv1.DaemonSetUpdateStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &zero},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateUpdateStrategy(test.strategy)
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateContainerResources(t *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}
+	tests := []struct {
+		name       string
+		config     *Config
+		containers []string
+		shouldErr  bool
+	}{
+		{
+			name:       "not set",
+			containers: speakerContainers,
+		},
+		{
+			name: "speaker sidecars",
+			config: &Config{ContainerResources: map[string]corev1.ResourceRequirements{
+				"frr":         resources,
+				"reloader":    resources,
+				"frr-metrics": resources,
+			}},
+			containers: speakerContainers,
+		},
+		{
+			name: "unknown speaker container",
+			config: &Config{ContainerResources: map[string]corev1.ResourceRequirements{
+				"frr-metric": resources,
+			}},
+			containers: speakerContainers,
+			shouldErr:  true,
+		},
+		{
+			name: "speaker container in controller",
+			config: &Config{ContainerResources: map[string]corev1.ResourceRequirements{
+				"frr": resources,
+			}},
+			containers: controllerContainers,
+			shouldErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateContainerResources(test.config, test.containers)
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []ObjectOverride
+		shouldErr bool
+	}{
+		{
+			name:      "strategic merge",
+			overrides: []ObjectOverride{{Kind: "DaemonSet", Name: "speaker", Patch: "metadata:\n  labels:\n    foo: bar\n"}},
+		},
+		{
+			name:      "json patch",
+			overrides: []ObjectOverride{{Kind: "DaemonSet", Name: "speaker", Type: JSONPatch, Patch: `[{"op": "remove", "path": "/metadata/labels"}]`}},
+		},
+		{
+			name:      "no name",
+			overrides: []ObjectOverride{{Kind: "DaemonSet", Patch: "metadata: {}"}},
+			shouldErr: true,
+		},
+		{
+			name:      "invalid type",
+			overrides: []ObjectOverride{{Kind: "DaemonSet", Name: "speaker", Type: "Merge", Patch: "metadata: {}"}},
+			shouldErr: true,
+		},
+		{
+			name:      "empty patch",
+			overrides: []ObjectOverride{{Kind: "DaemonSet", Name: "speaker", Patch: " "}},
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metallb := &MetalLB{Spec: MetalLBSpec{Overrides: test.overrides}}
+			err := metallb.Validate()
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected nil error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("targets", func(t *testing.T) {
+		defer func() { OverridesValidator = nil }()
+		OverridesValidator = func(*MetalLB) error {
+			return errors.New("DaemonSet foo not found among the rendered objects")
+		}
+		metallb := &MetalLB{}
+		if _, err := metallb.ValidateCreate(context.Background(), metallb); err != nil {
+			t.Errorf("Expected nil error without overrides, got: %v", err)
+		}
+		metallb.Spec.Overrides = []ObjectOverride{{Kind: "DaemonSet", Name: "foo", Patch: "metadata: {}"}}
+		if _, err := metallb.ValidateCreate(context.Background(), metallb); err == nil {
+			t.Errorf("Expected error, got no error")
+		}
+		if _, err := metallb.ValidateUpdate(context.Background(), metallb, metallb); err == nil {
+			t.Errorf("Expected error, got no error")
+		}
+	})
+}
+
+func TestValidateImages(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      MetalLBSpec
+		shouldErr bool
+	}{
+		{
+			name: "registry",
+			spec: MetalLBSpec{ImageRegistry: "mirror.example.com"},
+		},
+		{
+			name: "registry with port and path",
+			spec: MetalLBSpec{ImageRegistry: "mirror.example.com:5000/metallb/images"},
+		},
+		{
+			name:      "registry with scheme",
+			spec:      MetalLBSpec{ImageRegistry: "https://mirror.example.com"},
+			shouldErr: true,
+		},
+		{
+			name:      "registry with tag",
+			spec:      MetalLBSpec{ImageRegistry: "mirror.example.com/metallb:v1"},
+			shouldErr: true,
+		},
+		{
+			name: "pull secrets",
+			spec: MetalLBSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull-secret"}}},
+		},
+		{
+			name:      "invalid pull secret",
+			spec:      MetalLBSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "Pull_Secret"}}},
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metallb := &MetalLB{Spec: test.spec}
+			err := metallb.Validate()
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected nil error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("deprecated image", func(t *testing.T) {
+		metallb := &MetalLB{Spec: MetalLBSpec{MetalLBImage: "quay.io/metallb/speaker"}}
+		warnings, err := metallb.ValidateCreate(context.Background(), metallb)
+		if err != nil {
+			t.Errorf("Expected nil error, got: %v", err)
+		}
+		if len(warnings) != 1 {
+			t.Errorf("Expected a deprecation warning, got: %v", warnings)
+		}
+	})
+}
+
+func TestValidateMonitoring(t *testing.T) {
+	tests := []struct {
+		name      string
+		alerts    *AlertsConfig
+		shouldErr bool
+	}{
+		{
+			name: "no alerts",
+		},
+		{
+			name: "valid",
+			alerts: &AlertsConfig{
+				Severities:       map[string]string{"MetalLBStaleConfig": "critical", "FRRK8sBFDSessionDown": "warning"},
+				AdditionalLabels: map[string]string{"prometheus.io/rules": "k8s"},
+			},
+		},
+		{
+			name:      "unknown alert",
+			alerts:    &AlertsConfig{Severities: map[string]string{"MetalLBAddressPoolUsage75Percent": "critical"}},
+			shouldErr: true,
+		},
+		{
+			name:      "empty severity",
+			alerts:    &AlertsConfig{Severities: map[string]string{"MetalLBStaleConfig": ""}},
+			shouldErr: true,
+		},
+		{
+			name:      "invalid label",
+			alerts:    &AlertsConfig{AdditionalLabels: map[string]string{"prometheus rules": "k8s"}},
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metallb := &MetalLB{Spec: MetalLBSpec{Monitoring: &MonitoringConfig{Alerts: test.alerts}}}
+			err := metallb.Validate()
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected nil error, got: %v", err)
+			}
+		})
+	}
+
+	monitorTests := []struct {
+		name       string
+		monitoring *MonitoringConfig
+		shouldErr  bool
+	}{
+		{
+			name: "valid",
+			monitoring: &MonitoringConfig{
+				Type:              PodMonitorType,
+				Interval:          &metav1.Duration{Duration: time.Minute},
+				AdditionalLabels:  map[string]string{"prometheus": "k8s"},
+				MetricRelabelings: []RelabelConfig{{SourceLabels: []string{"__name__"}, Regex: "metallb_(.*)", Action: "keep"}},
+			},
+		},
+		{
+			name:       "invalid type",
+			monitoring: &MonitoringConfig{Type: "Probe"},
+			shouldErr:  true,
+		},
+		{
+			name:       "fractional interval",
+			monitoring: &MonitoringConfig{Interval: &metav1.Duration{Duration: 1500 * time.Millisecond}},
+			shouldErr:  true,
+		},
+		{
+			name:       "invalid label",
+			monitoring: &MonitoringConfig{AdditionalLabels: map[string]string{"prometheus": "k8s rules"}},
+			shouldErr:  true,
+		},
+		{
+			name:       "invalid regex",
+			monitoring: &MonitoringConfig{MetricRelabelings: []RelabelConfig{{Regex: "metallb_(.*"}}},
+			shouldErr:  true,
+		},
+	}
+	for _, test := range monitorTests {
+		t.Run(test.name, func(t *testing.T) {
+			metallb := &MetalLB{Spec: MetalLBSpec{Monitoring: test.monitoring}}
+			err := metallb.Validate()
+			if test.shouldErr && err == nil {
+				t.Errorf("Expected error, got no error")
+			}
+			if !test.shouldErr && err != nil {
+				t.Errorf("Expected nil error, got: %v", err)
+			}
+		})
+	}
 }
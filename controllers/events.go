@@ -0,0 +1,19 @@
+package controllers
+
+// The reasons of the events recorded on the MetalLB resource.
+const (
+	reasonBackendSwitched      = "BackendSwitched"
+	reasonOperandApplied       = "OperandApplied"
+	reasonApplyFailed          = "ApplyFailed"
+	reasonInvalidSpec          = "InvalidSpec"
+	reasonFRRK8sDeployedViaCNO = "FRRK8sDeployedViaCNO"
+	reasonObjectPruned         = "ObjectPruned"
+)
+
+// The actions reported by the events, describing what the operator did.
+const (
+	actionValidate = "Validate"
+	actionApply    = "Apply"
+	actionPrune    = "Prune"
+	actionDeploy   = "Deploy"
+)
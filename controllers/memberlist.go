@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/params"
+)
+
+const (
+	// memberlistKeyRotatedAtAnnotation records on the memberlist Secret when
+	// its key was generated.
+	memberlistKeyRotatedAtAnnotation = "metallb.io/memberlist-key-rotated-at"
+	// memberlistKeyHashAnnotation is set on the speaker pods, so that they are
+	// rolled out when the memberlist key changes.
+	memberlistKeyHashAnnotation = "metallb.io/memberlist-key-hash"
+	// memberlistSecretKey is the Secret key the speakers read the memberlist
+	// key from.
+	memberlistSecretKey = "secretkey"
+	memberlistKeySize   = 128
+	// memberlistPlaceholderKey stands for the memberlist key when rendering
+	// the operands without accessing the cluster, as the key is generated by
+	// the operator.
+	memberlistPlaceholderKey = "generated-by-the-operator"
+)
+
+// withMemberlistSecret adds the Secret holding the memberlist key to the
+// rendered objects and annotates the speaker pods with the hash of the key.
+// The current key is kept until it is due for rotation.
+func (r *MetalLBReconciler) withMemberlistSecret(ctx context.Context, config *metallbv1beta1.MetalLB, objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if !params.MemberlistEnabled(config) {
+		return objs, nil
+	}
+	secret, err := r.memberlistSecret(ctx, config, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return withMemberlistKey(objs, secret)
+}
+
+// withMemberlistKey adds the given memberlist Secret to the rendered objects
+// and annotates the speaker pods with the hash of its key.
+func withMemberlistKey(objs []*unstructured.Unstructured, secret *corev1.Secret) ([]*unstructured.Unstructured, error) {
+	for _, obj := range objs {
+		if obj.GetKind() != "DaemonSet" || obj.GetName() != "speaker" {
+			continue
+		}
+		hash := sha256.Sum256(secret.Data[memberlistSecretKey])
+		err := unstructured.SetNestedField(obj.Object, hex.EncodeToString(hash[:]), "spec", "template", "metadata", "annotations", memberlistKeyHashAnnotation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not annotate the speaker pods with the memberlist key hash")
+		}
+	}
+	res, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret)
+	if err != nil {
+		return nil, err
+	}
+	// The Secret is applied first, so that the speakers find it when starting.
+	return append([]*unstructured.Unstructured{{Object: res}}, objs...), nil
+}
+
+// newMemberlistSecret returns the Secret holding the memberlist key for the
+// given MetalLB resource, without the key.
+func newMemberlistSecret(config *metallbv1beta1.MetalLB, namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      params.MemberlistSecretName(config),
+			Namespace: namespace,
+			Labels:    map[string]string{managedByLabel: fieldManager},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+// memberlistSecret returns the Secret holding the memberlist key, generating a
+// new key if none exists or if the current one is due for rotation.
+func (r *MetalLBReconciler) memberlistSecret(ctx context.Context, config *metallbv1beta1.MetalLB, now time.Time) (*corev1.Secret, error) {
+	res := newMemberlistSecret(config, r.Namespace)
+
+	current := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: res.Name, Namespace: res.Namespace}, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "could not get the memberlist secret")
+	}
+
+	if err == nil {
+		key := current.Data[memberlistSecretKey]
+		rotatedAt, err := time.Parse(time.RFC3339, current.Annotations[memberlistKeyRotatedAtAnnotation])
+		if err != nil {
+			// The key was not generated by the operator, so it is adopted as
+			// if it was just rotated.
+			rotatedAt = now
+		}
+		if len(key) > 0 && current.Annotations[unmanagedAnnotation] == "true" {
+			// The key is managed by the user, so it is never rotated.
+			res.Data = map[string][]byte{memberlistSecretKey: key}
+			return res, nil
+		}
+		rotationIn, rotated := memberlistKeyRotationIn(config, rotatedAt, now)
+		keep := !rotated || rotationIn > 0
+		if len(key) > 0 && !keep {
+			// The speakers take a single key, so a new one is generated only
+			// once all of them run with the current one.
+			keep, err = r.speakerRolloutInProgress(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(key) > 0 && keep {
+			res.Annotations = map[string]string{memberlistKeyRotatedAtAnnotation: rotatedAt.UTC().Format(time.RFC3339)}
+			res.Data = map[string][]byte{memberlistSecretKey: key}
+			return res, nil
+		}
+	}
+
+	key := make([]byte, memberlistKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrapf(err, "could not generate the memberlist key")
+	}
+	res.Annotations = map[string]string{memberlistKeyRotatedAtAnnotation: now.UTC().Format(time.RFC3339)}
+	res.Data = map[string][]byte{memberlistSecretKey: key}
+	return res, nil
+}
+
+// speakerRolloutInProgress tells if the speaker daemonset is being rolled out,
+// with some of the speakers not running the current pod template yet.
+func (r *MetalLBReconciler) speakerRolloutInProgress(ctx context.Context) (bool, error) {
+	speaker := &appsv1.DaemonSet{}
+	err := r.Get(ctx, types.NamespacedName{Name: "speaker", Namespace: r.Namespace}, speaker)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "could not get the speaker daemonset")
+	}
+	return speaker.Status.ObservedGeneration < speaker.Generation ||
+		speaker.Status.UpdatedNumberScheduled < speaker.Status.DesiredNumberScheduled ||
+		speaker.Status.NumberReady < speaker.Status.DesiredNumberScheduled, nil
+}
+
+// memberlistKeyRotationIn returns how long until the memberlist key generated
+// at the given time is due for rotation, and false if the key is never
+// rotated.
+func memberlistKeyRotationIn(config *metallbv1beta1.MetalLB, rotatedAt, now time.Time) (time.Duration, bool) {
+	if config.Spec.Memberlist == nil || config.Spec.Memberlist.KeyRotationInterval == nil {
+		return 0, false
+	}
+	return rotatedAt.Add(config.Spec.Memberlist.KeyRotationInterval.Duration).Sub(now), true
+}
+
+// memberlistRequeueAfter returns when the reconciliation must run again to
+// rotate the memberlist key found among the applied objects, or zero if the
+// key is not rotated.
+func memberlistRequeueAfter(config *metallbv1beta1.MetalLB, applied []*unstructured.Unstructured, now time.Time) time.Duration {
+	for _, obj := range applied {
+		if obj.GetKind() != "Secret" || obj.GetName() != params.MemberlistSecretName(config) {
+			continue
+		}
+		rotatedAt, err := time.Parse(time.RFC3339, obj.GetAnnotations()[memberlistKeyRotatedAtAnnotation])
+		if err != nil {
+			return 0
+		}
+		rotationIn, rotated := memberlistKeyRotationIn(config, rotatedAt, now)
+		if !rotated {
+			return 0
+		}
+		return max(rotationIn, time.Second)
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+)
+
+var _ = Describe("Memberlist", func() {
+	It("Should requeue when the key is due for rotation", func() {
+		now := time.Now().Truncate(time.Second)
+		config := &metallbv1beta1.MetalLB{}
+		secret := &unstructured.Unstructured{}
+		secret.SetKind("Secret")
+		secret.SetName("metallb-memberlist")
+		secret.SetAnnotations(map[string]string{
+			memberlistKeyRotatedAtAnnotation: now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		})
+		applied := []*unstructured.Unstructured{secret}
+
+		Expect(memberlistRequeueAfter(config, applied, now)).To(BeZero())
+
+		config.Spec.Memberlist = &metallbv1beta1.MemberlistConfig{
+			KeyRotationInterval: &metav1.Duration{Duration: 3 * time.Hour},
+		}
+		Expect(memberlistRequeueAfter(config, applied, now)).To(Equal(2 * time.Hour))
+
+		config.Spec.Memberlist.KeyRotationInterval.Duration = time.Hour / 2
+		Expect(memberlistRequeueAfter(config, applied, now)).To(Equal(time.Second))
+
+		config.Spec.Memberlist.SecretName = "other"
+		Expect(memberlistRequeueAfter(config, applied, now)).To(BeZero())
+	})
+})
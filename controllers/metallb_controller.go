@@ -18,17 +18,39 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
 	"github.com/metallb/metallb-operator/pkg/helm"
@@ -46,6 +68,21 @@ const (
 	FRRK8SChartPathController  = "./bindata/deployment/helm/frr-k8s"
 )
 
+const (
+	// metallbFinalizer guards the removal of the cluster scoped operands.
+	metallbFinalizer = "metallb.io/cluster-resources"
+	// managedByLabel marks the objects applied by the operator, so the ones
+	// not rendered anymore can be found and pruned.
+	managedByLabel = "metallb.io/managed-by"
+	// pausedAnnotation, when set to "true" on the MetalLB resource, stops the
+	// operator from applying the operands.
+	pausedAnnotation = "metallb.io/reconcile-paused"
+	// unmanagedAnnotation, when set to "true" on a deployed object, excludes it
+	// from being applied or pruned by the operator. It is honored on the kinds
+	// watched by the operator, listed in cachedKinds.
+	unmanagedAnnotation = "metallb.io/unmanaged"
+)
+
 // MetalLBReconciler reconciles a MetalLB object
 type MetalLBReconciler struct {
 	client.Client
@@ -55,6 +92,58 @@ type MetalLBReconciler struct {
 	Scheme       *runtime.Scheme
 	Namespace    string
 	EnvConfig    params.EnvConfig
+
+	// externalFRRK8sBackoff spaces the retries while waiting for an externally
+	// managed frr-k8s to become available.
+	externalFRRK8sBackoff workqueue.TypedRateLimiter[ctrl.Request]
+
+	// The monitoring kinds are watched only once their CRDs are installed, so
+	// the watches are added to the controller while reconciling.
+	discovery             discovery.DiscoveryInterface
+	controller            controller.Controller
+	cache                 cache.Cache
+	monitoringWatches     map[string]bool
+	monitoringWatchesLock sync.Mutex
+
+	renderCache renderCache
+	recorder    events.EventRecorder
+	// appliedObjects tracks the last applied version of each object, to tell
+	// the drift corrections apart from the changes of the rendered objects.
+	appliedObjects map[string]appliedObject
+}
+
+// appliedObject describes the last version of an object applied by the operator.
+type appliedObject struct {
+	// hash is the hash of the rendered object.
+	hash string
+	// resourceVersion is the version of the object returned by the apply.
+	resourceVersion string
+}
+
+// cachedKinds are the kinds of the operands watched by the operator, whose
+// deployed objects are read from the informers of the manager.
+var cachedKinds = map[schema.GroupVersionKind]bool{
+	appsv1.SchemeGroupVersion.WithKind("Deployment"):          true,
+	appsv1.SchemeGroupVersion.WithKind("DaemonSet"):           true,
+	corev1.SchemeGroupVersion.WithKind("Service"):             true,
+	corev1.SchemeGroupVersion.WithKind("Secret"):              true,
+	networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"): true,
+}
+
+// prunableKinds are the kinds of the rendered objects that are removed when
+// they are not rendered anymore. RBAC objects and the SCC are not listed here
+// as the operator is not allowed to list them.
+var prunableKinds = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "", Version: "v1", Kind: "ConfigMap"},
+	{Group: "", Version: "v1", Kind: "Secret"},
+	{Group: "", Version: "v1", Kind: "Service"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"},
+	{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+	{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"},
+	{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"},
 }
 
 var MetalLBChartPath = MetalLBChartPathController
@@ -62,26 +151,43 @@ var FRRK8SChartPath = FRRK8SChartPathController
 
 var EmbeddedFRRK8sSupportNotAvailable = errors.New("current CNO version does not support deploying frr-k8s")
 
+// reasonInvalidMetalLBSpec is the reason of the Degraded condition set when the
+// MetalLB resource fails the validation.
+const reasonInvalidMetalLBSpec = "InvalidMetalLBSpec"
+
+// invalidSpecError is returned when the MetalLB resource fails the validation,
+// so that it is reported as Degraded without applying the operands.
+type invalidSpecError struct {
+	err error
+}
+
+func (e invalidSpecError) Error() string { return e.err.Error() }
+
 // Namespace Scoped
 // +kubebuilder:rbac:groups=apps,namespace=metallb-system,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",namespace=metallb-system,resources=services,verbs=create;delete;get;update;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",namespace=metallb-system,resources=services,verbs=create;delete;get;list;watch;update;patch
 // +kubebuilder:rbac:groups="coordination.k8s.io",namespace=metallb-system,resources=leases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",namespace=metallb-system,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=events.k8s.io,namespace=metallb-system,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",namespace=metallb-system,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",namespace=metallb-system,resources=pods,verbs=get;list;watch
 
 // Cluster Scoped
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=list;watch
 // +kubebuilder:rbac:groups=metallb.io,resources=metallbs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metallb.io,resources=metallbs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=policy,resources=podsecuritypolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metallb.io,resources=metallbs/finalizers,verbs=delete;get;update;patch
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=create;delete;get;update;patch;list;watch
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;update;patch;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=create;delete;get;update;patch;list;watch
 // +kubebuilder:rbac:groups=operator.openshift.io,resources=networks,verbs=get;list;watch;update;
-// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=metallb-speaker,verbs=create;patch
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=metallb-speaker,verbs=create;get;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=apiservers;clusteroperators,verbs=get;list;watch;
 
 func (r *MetalLBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -104,7 +210,8 @@ func (r *MetalLBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if req.Name != defaultMetalLBCrName {
 		err := fmt.Errorf("MetalLB resource name must be '%s'", defaultMetalLBCrName)
 		logger.Error(err, "Invalid MetalLB resource name", "name", req.Name)
-		if err := status.Update(context.TODO(), r.Client, instance, status.ConditionDegraded, "IncorrectMetalLBResourceName", fmt.Sprintf("Incorrect MetalLB resource name: %s", req.Name)); err != nil {
+		r.recorder.Eventf(instance, nil, corev1.EventTypeWarning, reasonInvalidSpec, actionValidate, "%v", err)
+		if err := status.Update(context.TODO(), r.Client, instance, nil, status.ConditionDegraded, "IncorrectMetalLBResourceName", fmt.Sprintf("Incorrect MetalLB resource name: %s", req.Name)); err != nil {
 			logger.Error(err, "Failed to update metallb status", "Desired status", status.ConditionDegraded)
 			return ctrl.Result{}, nil // Return success to avoid requeue
 		}
@@ -112,16 +219,78 @@ func (r *MetalLBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil // Return success to avoid requeue
 	}
 
-	result, condition, err := r.reconcileResource(ctx, req, instance)
+	if !instance.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(instance, metallbFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteClusterScopedResources(ctx, instance); err != nil {
+			logger.Error(err, "Failed to delete cluster scoped resources")
+			return ctrl.Result{}, err
+		}
+		// The operands are garbage collected with the MetalLB resource.
+		r.appliedObjects = map[string]appliedObject{}
+		controllerutil.RemoveFinalizer(instance, metallbFinalizer)
+		return ctrl.Result{}, r.Update(ctx, instance)
+	}
+
+	if controllerutil.AddFinalizer(instance, metallbFinalizer) {
+		if err := r.Update(ctx, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if instance.Annotations[pausedAnnotation] == "true" {
+		logger.Info("reconciliation paused", "annotation", pausedAnnotation)
+		msg := fmt.Sprintf("Reconciliation paused via the %s annotation", pausedAnnotation)
+		if err := status.UpdatePaused(ctx, r.Client, instance, msg); err != nil {
+			logger.Error(err, "Failed to update metallb status", "Desired status", status.ConditionPaused)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Spec.ApplyMode == metallbv1beta1.ApplyModePreview {
+		changes, err := r.previewMetalLBResources(ctx, instance)
+		if err != nil {
+			logger.Error(err, "Failed to preview the changes to the operands")
+			return ctrl.Result{}, err
+		}
+		if err := status.UpdatePreview(ctx, r.Client, instance, changes); err != nil {
+			logger.Error(err, "Failed to update metallb status", "Desired status", status.ConditionPreview)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	result, condition, components, err := r.reconcileResource(ctx, req, instance)
+	if len(components) > 0 {
+		setOperandReadiness(components)
+	}
 	if condition != "" {
 		errorMsg, wrappedErrMsg := condition, ""
-		if err != nil {
+		var notReady status.MetalLBResourcesNotReadyError
+		var externalNotReady status.ExternalFRRK8sNotReadyError
+		var invalidSpec invalidSpecError
+		switch {
+		case errors.As(err, &invalidSpec):
+			errorMsg, wrappedErrMsg = reasonInvalidMetalLBSpec, invalidSpec.Error()
+		case errors.As(err, &notReady):
+			errorMsg, wrappedErrMsg = "ComponentsNotReady", notReady.Message
+		case errors.As(err, &externalNotReady):
+			errorMsg, wrappedErrMsg = status.ReasonExternalFRRK8sNotReady, externalNotReady.Message
+		case err != nil:
 			errorMsg = "internal error"
 			if errors.Unwrap(err) != nil {
 				wrappedErrMsg = errors.Unwrap(err).Error()
 			}
 		}
-		if err := status.Update(context.TODO(), r.Client, instance, condition, errorMsg, wrappedErrMsg); err != nil {
+		// The effective spec is recorded only once the operands are applied,
+		// as it tells the backend the switches are reported from.
+		var effective *metallbv1beta1.EffectiveSpec
+		if len(components) > 0 {
+			effective = params.EffectiveSpec(instance, r.EnvConfig)
+		}
+		if err := status.Update(context.TODO(), r.Client, instance, effective, condition, errorMsg, wrappedErrMsg, components...); err != nil {
 			logger.Error(err, "Failed to update metallb status", "Desired status", condition)
 			return ctrl.Result{}, err
 		}
@@ -130,26 +299,66 @@ func (r *MetalLBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return result, nil
 }
 
-func (r *MetalLBReconciler) reconcileResource(ctx context.Context, req ctrl.Request, instance *metallbv1beta1.MetalLB) (ctrl.Result, string, error) {
-	err := r.syncMetalLBResources(ctx, instance)
+func (r *MetalLBReconciler) reconcileResource(ctx context.Context, req ctrl.Request, instance *metallbv1beta1.MetalLB) (ctrl.Result, string, []metav1.Condition, error) {
+	objs, unavailableMonitoring, err := r.syncMetalLBResources(ctx, instance)
 	if errors.Is(err, EmbeddedFRRK8sSupportNotAvailable) {
-		return ctrl.Result{RequeueAfter: 2 * time.Minute}, "", nil
+		return ctrl.Result{RequeueAfter: 2 * time.Minute}, "", nil, nil
 	}
 	if err != nil {
-		return ctrl.Result{}, status.ConditionDegraded, errors.Wrapf(err, "FailedToSyncMetalLBResources")
+		return ctrl.Result{}, status.ConditionDegraded, nil, errors.Wrapf(err, "FailedToSyncMetalLBResources")
 	}
-	err = status.IsMetalLBAvailable(context.TODO(), r.Client, req.Namespace)
-	if err != nil {
-		if _, ok := err.(status.MetalLBResourcesNotReadyError); ok {
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, status.ConditionProgressing, nil
+	// The backend in effect is recorded in the status, so the switches are
+	// reported also across restarts of the operator.
+	bgpType := params.BGPType(instance, r.EnvConfig)
+	if previous := instance.Status.EffectiveSpec; previous != nil && previous.BGPBackend != bgpType {
+		r.recorder.Eventf(instance, nil, corev1.EventTypeNormal, reasonBackendSwitched, actionApply, "BGP backend switched from %s to %s", previous.BGPBackend, bgpType)
+	}
+	setBGPBackendInfo(bgpType)
+
+	defer observePhase(phaseStatus, time.Now())
+	components, err := status.IsMetalLBAvailable(context.TODO(), r.Client, status.Components(objs))
+	_, notReady := err.(status.MetalLBResourcesNotReadyError)
+	if err != nil && !notReady {
+		return ctrl.Result{}, status.ConditionProgressing, nil, err
+	}
+	if len(unavailableMonitoring) > 0 {
+		components = append(components, status.MonitoringUnavailable(unavailableMonitoring))
+	}
+
+	if params.BGPType(instance, r.EnvConfig) == metallbv1beta1.FRRK8sExternalMode {
+		frrk8sCondition, err := status.IsExternalFRRK8sAvailable(ctx, r.Client, params.FRRK8sNamespace(instance, r.EnvConfig))
+		if _, ok := err.(status.ExternalFRRK8sNotReadyError); ok {
+			components = append(components, frrk8sCondition)
+			return ctrl.Result{RequeueAfter: r.externalFRRK8sBackoff.When(req)}, status.ConditionDegraded, components, err
+		}
+		if err != nil {
+			return ctrl.Result{}, status.ConditionProgressing, nil, err
 		}
-		return ctrl.Result{}, status.ConditionProgressing, err
+		r.externalFRRK8sBackoff.Forget(req)
+		components = append(components, frrk8sCondition)
 	}
-	return ctrl.Result{}, status.ConditionAvailable, nil
+
+	result := ctrl.Result{RequeueAfter: memberlistRequeueAfter(instance, objs, time.Now())}
+	if notReady {
+		// The owned workloads and the endpoints are watched, so their readiness
+		// changes trigger a new reconciliation.
+		return result, status.ConditionProgressing, components, err
+	}
+	return result, status.ConditionAvailable, components, nil
 }
 
 func (r *MetalLBReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.externalFRRK8sBackoff = workqueue.NewTypedItemExponentialFailureRateLimiter[ctrl.Request](5*time.Second, 5*time.Minute)
+
 	var err error
+	r.discovery, err = discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r.cache = mgr.GetCache()
+	r.monitoringWatches = map[string]bool{}
+	r.appliedObjects = map[string]appliedObject{}
+	r.recorder = mgr.GetEventRecorder("metallb-operator")
 	r.metalLBChart, err = helm.NewMetalLBChart(MetalLBChartPath, defaultMetalLBCrName, r.Namespace, r.Client)
 	if err != nil {
 		return err
@@ -159,18 +368,49 @@ func (r *MetalLBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	operandsPredicate := builder.WithPredicates(desiredStateChanged())
+	workloadsPredicate := builder.WithPredicates(predicate.Or(desiredStateChanged(), workloadReadinessChanged()))
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&metallbv1beta1.MetalLB{}).
+		Owns(&appsv1.Deployment{}, workloadsPredicate).
+		Owns(&appsv1.DaemonSet{}, workloadsPredicate).
+		Owns(&corev1.Service{}, operandsPredicate).
+		Owns(&networkingv1.NetworkPolicy{}, operandsPredicate).
+		Owns(&corev1.Secret{}, operandsPredicate).
+		// The endpoints are owned by the services, so they are mapped to the
+		// MetalLB instance to track the readiness of the webhooks.
+		Watches(&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				if obj.GetNamespace() != r.Namespace {
+					return nil
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: defaultMetalLBCrName, Namespace: r.Namespace}}}
+			}),
+			builder.WithPredicates(endpointsReadinessChanged())).
+		// The monitoring objects are skipped while their CRDs are not installed,
+		// so the MetalLB instance is reconciled again when they show up.
+		Watches(&apiext.CustomResourceDefinition{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: defaultMetalLBCrName, Namespace: r.Namespace}}}
+			}),
+			// Only the metadata is cached, as the CRDs are large and the name
+			// of a CRD ends with its group.
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return strings.HasSuffix(obj.GetName(), "."+monitoringGroup)
+			})))
+
 	if r.EnvConfig.IsOpenshift {
-		return ctrl.NewControllerManagedBy(mgr).
-			For(&metallbv1beta1.MetalLB{}).
-			Watches(&openshiftapiv1.Network{}, &handler.EnqueueRequestForObject{}).
-			Complete(r)
+		bldr = bldr.Watches(&openshiftapiv1.Network{}, &handler.EnqueueRequestForObject{})
 	}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&metallbv1beta1.MetalLB{}).
-		Complete(r)
+	r.controller, err = bldr.Build(r)
+	return err
 }
 
-func (r *MetalLBReconciler) syncMetalLBResources(ctx context.Context, config *metallbv1beta1.MetalLB) error {
+// syncMetalLBResources renders and applies the operands, returning the objects
+// applied to the cluster and the monitoring kinds skipped because their CRDs
+// are not installed.
+func (r *MetalLBReconciler) syncMetalLBResources(ctx context.Context, config *metallbv1beta1.MetalLB) ([]*unstructured.Unstructured, []string, error) {
 	logger := r.Log.WithName("syncMetalLBResources")
 	logger.Info("Start Reconciling")
 
@@ -178,72 +418,307 @@ func (r *MetalLBReconciler) syncMetalLBResources(ctx context.Context, config *me
 	if r.EnvConfig.MustDeployFRRK8sFromCNO && r.EnvConfig.IsOpenshift && (bgpType == metallbv1beta1.FRRK8sExternalMode) {
 		supportsFRRK8s, err := openshift.SupportsFRRK8s(ctx, r.Client, r.EnvConfig)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if !supportsFRRK8s {
-			return EmbeddedFRRK8sSupportNotAvailable
+			return nil, nil, EmbeddedFRRK8sSupportNotAvailable
 		}
 
-		if err := openshift.DeployFRRK8s(ctx, r.Client); err != nil {
-			return err
+		added, err := openshift.DeployFRRK8s(ctx, r.Client)
+		if err != nil {
+			return nil, nil, err
+		}
+		if added {
+			r.recorder.Eventf(config, nil, corev1.EventTypeNormal, reasonFRRK8sDeployedViaCNO, actionDeploy, "Requested the cluster network operator to deploy frr-k8s")
 		}
 	}
 
 	err := config.Validate()
 	if err != nil {
 		r.Log.Error(err, "Invalid MetalLB resource")
-		return nil
+		r.recorder.Eventf(config, nil, corev1.EventTypeWarning, reasonInvalidSpec, actionValidate, "%v", err)
+		return nil, nil, invalidSpecError{err: err}
 	}
 
 	err = validateBGPMode(config, r.EnvConfig.IsOpenshift)
 	if err != nil {
-		return err
+		r.recorder.Eventf(config, nil, corev1.EventTypeWarning, reasonInvalidSpec, actionValidate, "%v", err)
+		return nil, nil, invalidSpecError{err: err}
 	}
-	objs := []*unstructured.Unstructured{}
-	toDel := []*unstructured.Unstructured{}
-	frrk8sObjs, err := r.frrk8sChart.Objects(r.EnvConfig, config)
+	renderStart := time.Now()
+	objs, toDel, err := r.renderCache.render(r.metalLBChart, r.frrk8sChart, r.EnvConfig, config)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-
-	if bgpType == metallbv1beta1.FRRK8sMode {
-		objs = append(objs, frrk8sObjs...)
-	} else {
-		toDel = append(toDel, frrk8sObjs...)
+	objs, err = r.withMemberlistSecret(ctx, config, objs)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	mlbObjs, err := r.metalLBChart.Objects(r.EnvConfig, config)
+	objs, unavailableMonitoring, err := r.withoutUnavailableMonitoring(objs)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if len(unavailableMonitoring) > 0 {
+		logger.Info("skipping the monitoring objects whose CRDs are not installed", "kinds", unavailableMonitoring)
 	}
-	objs = append(objs, mlbObjs...)
+	observePhase(phaseRender, renderStart)
 
+	defer observePhase(phaseApply, time.Now())
 	for _, obj := range toDel {
 		err := r.Delete(context.Background(), obj)
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return nil, nil, errors.Wrapf(err, "could not delete (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		}
+		if err == nil {
+			r.recordPruned(config, obj)
+		}
+	}
+
+	applied := []*unstructured.Unstructured{}
+	changed := 0
+	for _, obj := range objs {
+		existing, err := r.cachedObject(ctx, obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing != nil && existing.GetAnnotations()[unmanagedAnnotation] == "true" {
+			logger.Info("skipping unmanaged object", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+			applied = append(applied, obj)
+			continue
+		}
+		hash, err := objectHash(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := r.apply(ctx, config, obj); err != nil {
+			r.recorder.Eventf(config, obj, corev1.EventTypeWarning, reasonApplyFailed, actionApply, "%v", err)
+			return nil, nil, err
+		}
+		appliedObjects.WithLabelValues(obj.GetKind()).Inc()
+		key := objectKey(obj)
+		previous, known := r.appliedObjects[key]
+		if !known && existing != nil {
+			previous = appliedObject{resourceVersion: existing.GetResourceVersion()}
+		}
+		if previous.resourceVersion != obj.GetResourceVersion() {
+			changed++
+		}
+		if known && previous.resourceVersion != obj.GetResourceVersion() && previous.hash == hash {
+			// The rendered object did not change since the last apply, so
+			// the deployed one was modified by someone else.
+			logger.Info("reverted drifted object", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+			driftCorrections.WithLabelValues(obj.GetKind()).Inc()
+		}
+		r.appliedObjects[key] = appliedObject{hash: hash, resourceVersion: obj.GetResourceVersion()}
+		applied = append(applied, obj)
+	}
+
+	if err := r.pruneResources(ctx, config, applied); err != nil {
+		return nil, nil, err
+	}
+	if changed > 0 {
+		r.recorder.Eventf(config, nil, corev1.EventTypeNormal, reasonOperandApplied, actionApply, "Applied %d changed objects out of %d", changed, len(applied))
+	}
+	return applied, unavailableMonitoring, nil
+}
+
+// existingObject returns the deployed counterpart of the given rendered object,
+// or nil if it does not exist. The object is read from the API server, so this
+// is reserved to the preview of the changes.
+func (r *MetalLBReconciler) existingObject(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return existing, nil
+}
+
+// cachedObject returns the deployed counterpart of the given rendered object
+// from the informers of the manager, or nil if it does not exist or if its kind
+// is not watched by the operator.
+func (r *MetalLBReconciler) cachedObject(ctx context.Context, obj *unstructured.Unstructured) (client.Object, error) {
+	gvk := obj.GroupVersionKind()
+	if !cachedKinds[gvk] {
+		return nil, nil
+	}
+	runtimeObj, err := r.Scheme.New(gvk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create (%s)", gvk)
+	}
+	existing := runtimeObj.(client.Object)
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get (%s) %s/%s", gvk, obj.GetNamespace(), obj.GetName())
+	}
+	return existing, nil
+}
+
+// apply server side applies the given rendered object, setting the MetalLB
+// resource as its owner. The object is updated with the result of the apply.
+func (r *MetalLBReconciler) apply(ctx context.Context, config *metallbv1beta1.MetalLB, obj *unstructured.Unstructured, opts ...client.ApplyOption) error {
+	objNS := obj.GetNamespace()
+	namespaced, err := r.IsObjectNamespaced(obj)
+	if err != nil {
+		return errors.Wrapf(err, "could not find the scope of (%s) %s", obj.GroupVersionKind(), obj.GetName())
+	}
+	if namespaced { // Avoid setting reference on a cluster-scoped resource, those are removed by the finalizer.
+		if err := controllerutil.SetControllerReference(config, obj, r.Scheme); err != nil {
+			return errors.Wrapf(err, "Failed to set controller reference to %s %s", objNS, obj.GetName())
+		}
+	}
+	applyConfig := client.ApplyConfigurationFromUnstructured(obj)
+	opts = append([]client.ApplyOption{client.FieldOwner(fieldManager), client.ForceOwnership}, opts...)
+	if err := r.Apply(ctx, applyConfig, opts...); err != nil {
+		return errors.Wrapf(err, "could not apply (%s) %s/%s", obj.GroupVersionKind(), objNS, obj.GetName())
+	}
+	return nil
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// objectHash returns the hash of the given rendered object, to detect the
+// changes between two renderings.
+func objectHash(obj *unstructured.Unstructured) (string, error) {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not marshal (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pruneResources deletes the objects labeled as managed by the operator that
+// are not part of the currently applied ones.
+func (r *MetalLBReconciler) pruneResources(ctx context.Context, config *metallbv1beta1.MetalLB, applied []*unstructured.Unstructured) error {
+	toPrune, err := r.pruneCandidates(ctx, applied)
+	if err != nil {
+		return err
+	}
+	for _, obj := range toPrune {
+		r.Log.Info("pruning object not rendered anymore", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		err := r.Delete(ctx, obj)
 		if err != nil && !apierrors.IsNotFound(err) {
 			return errors.Wrapf(err, "could not delete (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 		}
+		if err == nil {
+			r.recordPruned(config, obj)
+		}
 	}
+	return nil
+}
 
-	for _, obj := range objs {
-		objKind := obj.GetKind()
-		// Skip applying role and role binding object, because with the operator these are being set outside,
-		// either in manifests or via the csv.
-		if objKind == "Role" || objKind == "RoleBinding" {
+// recordPruned reports the deletion of an operand object that is not rendered
+// anymore.
+func (r *MetalLBReconciler) recordPruned(config *metallbv1beta1.MetalLB, obj *unstructured.Unstructured) {
+	prunedObjects.WithLabelValues(obj.GetKind()).Inc()
+	r.recorder.Eventf(config, obj, corev1.EventTypeNormal, reasonObjectPruned, actionPrune, "Deleted (%s) %s/%s, not rendered anymore", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// pruneCandidates returns the objects labeled as managed by the operator that
+// are not part of the given ones.
+func (r *MetalLBReconciler) pruneCandidates(ctx context.Context, applied []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	type objKey struct {
+		gk        schema.GroupKind
+		namespace string
+		name      string
+	}
+	keyFor := func(obj *unstructured.Unstructured, namespaced bool) objKey {
+		key := objKey{gk: obj.GroupVersionKind().GroupKind(), name: obj.GetName()}
+		if namespaced {
+			key.namespace = obj.GetNamespace()
+		}
+		return key
+	}
+
+	current := map[objKey]bool{}
+	for _, obj := range applied {
+		namespaced, err := r.IsObjectNamespaced(obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find the scope of (%s) %s", obj.GroupVersionKind(), obj.GetName())
+		}
+		current[keyFor(obj, namespaced)] = true
+	}
+
+	res := []*unstructured.Unstructured{}
+	for _, gvk := range prunableKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		sample := &unstructured.Unstructured{}
+		sample.SetGroupVersionKind(gvk)
+		namespaced, err := r.IsObjectNamespaced(sample)
+		if meta.IsNoMatchError(err) { // The kind is not served by this cluster, so nothing to prune.
 			continue
 		}
-		objNS := obj.GetNamespace()
-		if objNS != "" { // Avoid setting reference on a cluster-scoped resource.
-			if err := controllerutil.SetControllerReference(config, obj, r.Scheme); err != nil {
-				return errors.Wrapf(err, "Failed to set controller reference to %s %s", objNS, obj.GetName())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find the scope of %s", gvk)
+		}
+		opts := []client.ListOption{client.MatchingLabels{managedByLabel: fieldManager}}
+		if namespaced {
+			opts = append(opts, client.InNamespace(r.Namespace))
+		}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, errors.Wrapf(err, "could not list %s", gvk)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if current[keyFor(obj, namespaced)] || obj.GetAnnotations()[unmanagedAnnotation] == "true" {
+				continue
 			}
+			res = append(res, obj)
+		}
+	}
+	return res, nil
+}
+
+// deleteClusterScopedResources removes the cluster-scoped objects the operator
+// created, as they can't be garbage collected via owner references. CRDs are
+// left in place to avoid deleting the users' configuration.
+func (r *MetalLBReconciler) deleteClusterScopedResources(ctx context.Context, config *metallbv1beta1.MetalLB) error {
+	frrk8sObjs, err := r.frrk8sChart.Objects(r.EnvConfig, config)
+	if err != nil {
+		return err
+	}
+	mlbObjs, err := r.metalLBChart.Objects(r.EnvConfig, config)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range append(frrk8sObjs, mlbObjs...) {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			continue
 		}
-		applyConfig := client.ApplyConfigurationFromUnstructured(obj)
-		if err := r.Apply(ctx, applyConfig, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
-			return errors.Wrapf(err, "could not apply (%s) %s/%s", obj.GroupVersionKind(), objNS, obj.GetName())
+		namespaced, err := r.IsObjectNamespaced(obj)
+		if meta.IsNoMatchError(err) { // The kind is not served by this cluster, so nothing to delete.
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not find the scope of (%s) %s", obj.GroupVersionKind(), obj.GetName())
+		}
+		if namespaced {
+			continue
+		}
+		obj.SetNamespace("")
+		err = r.Delete(ctx, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "could not delete (%s) %s", obj.GroupVersionKind(), obj.GetName())
 		}
 	}
 
+	if r.EnvConfig.MustDeployFRRK8sFromCNO && r.EnvConfig.IsOpenshift {
+		if err := openshift.RemoveFRRK8s(ctx, r.Client); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -6,15 +6,22 @@ import (
 	"time"
 
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/status"
 	"github.com/metallb/metallb-operator/test/consts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -317,6 +324,559 @@ var _ = Describe("MetalLB Controller", func() {
 			}
 
 		})
+		It("Should remove cluster scoped resources when deleted", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					BGPBackend: metallbv1beta1.FRRK8sMode,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the frr-k8s webhook configuration is created")
+			webhook := &admissionv1.ValidatingWebhookConfiguration{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: "frr-k8s-validating-webhook-configuration"}, webhook)
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+
+			By("Checking the finalizer is set")
+			Eventually(func() []string {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return nil
+				}
+				return toCheck.Finalizers
+			}, 2*time.Second, 200*time.Millisecond).Should(ContainElement(metallbFinalizer))
+
+			By("Deleting the MetalLB resource")
+			err = k8sClient.Delete(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the frr-k8s webhook configuration is removed")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "frr-k8s-validating-webhook-configuration"}, webhook)
+				return apierrors.IsNotFound(err)
+			}, 5*time.Second, 200*time.Millisecond).Should(BeTrue())
+
+			By("Checking the MetalLB resource is gone")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), &metallbv1beta1.MetalLB{})
+				return apierrors.IsNotFound(err)
+			}, 5*time.Second, 200*time.Millisecond).Should(BeTrue())
+		})
+		It("Should prune the objects not rendered anymore", func() {
+			stale := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: MetalLBTestNameSpace,
+					Labels:    map[string]string{managedByLabel: fieldManager},
+				},
+			}
+			unmanaged := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unmanaged",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+			for _, cm := range []*v1.ConfigMap{stale, unmanaged} {
+				err := k8sClient.Create(context.Background(), cm)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			defer func() {
+				err := k8sClient.Delete(context.Background(), unmanaged)
+				Expect(err).ToNot(HaveOccurred())
+			}()
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the applied objects are labeled")
+			Eventually(func() map[string]string {
+				speakerDaemonSet := &appsv1.DaemonSet{}
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}, speakerDaemonSet)
+				if err != nil {
+					return nil
+				}
+				return speakerDaemonSet.Labels
+			}, 2*time.Second, 200*time.Millisecond).Should(HaveKeyWithValue(managedByLabel, fieldManager))
+
+			By("Checking the stale object is pruned")
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(stale), &v1.ConfigMap{})
+				return apierrors.IsNotFound(err)
+			}, 5*time.Second, 200*time.Millisecond).Should(BeTrue())
+
+			By("Checking the unmanaged object is not pruned")
+			Consistently(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKeyFromObject(unmanaged), &v1.ConfigMap{})
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+		})
+		It("Should record events for the reconciliation outcomes", func() {
+			stale := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: MetalLBTestNameSpace,
+					Labels:    map[string]string{managedByLabel: fieldManager},
+				},
+			}
+			err := k8sClient.Create(context.Background(), stale)
+			Expect(err).ToNot(HaveOccurred())
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					BGPBackend: metallbv1beta1.FRRK8sMode,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err = k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			metallbEvents := func() []eventsv1.Event {
+				events := &eventsv1.EventList{}
+				err := k8sClient.List(context.Background(), events, client.InNamespace(MetalLBTestNameSpace))
+				if err != nil {
+					return nil
+				}
+				res := []eventsv1.Event{}
+				for _, e := range events.Items {
+					if e.Regarding.Kind == "MetalLB" && e.Regarding.UID == metallb.UID {
+						res = append(res, e)
+					}
+				}
+				return res
+			}
+
+			By("Checking the operands are reported as applied")
+			Eventually(metallbEvents, 5*time.Second, 200*time.Millisecond).Should(ContainElement(And(
+				HaveField("Reason", reasonOperandApplied),
+				HaveField("Action", actionApply),
+				HaveField("Type", v1.EventTypeNormal),
+			)))
+
+			By("Checking the stale object is reported as pruned")
+			Eventually(metallbEvents, 5*time.Second, 200*time.Millisecond).Should(ContainElement(And(
+				HaveField("Reason", reasonObjectPruned),
+				HaveField("Action", actionPrune),
+				HaveField("Related.Kind", "ConfigMap"),
+				HaveField("Related.Name", stale.Name),
+			)))
+
+			By("Checking the backend in effect is recorded")
+			Eventually(func() *metallbv1beta1.EffectiveSpec {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return nil
+				}
+				return toCheck.Status.EffectiveSpec
+			}, 5*time.Second, 200*time.Millisecond).Should(Equal(&metallbv1beta1.EffectiveSpec{BGPBackend: metallbv1beta1.FRRK8sMode}))
+
+			By("Switching to frr mode")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				toUpdate := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toUpdate)
+				if err != nil {
+					return err
+				}
+				toUpdate.Spec.BGPBackend = metallbv1beta1.FRRMode
+				return k8sClient.Update(context.Background(), toUpdate)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the backend switch is reported")
+			Eventually(metallbEvents, 5*time.Second, 200*time.Millisecond).Should(ContainElement(And(
+				HaveField("Reason", reasonBackendSwitched),
+				HaveField("Note", "BGP backend switched from frr-k8s to frr"),
+			)))
+		})
+		It("Should report an invalid spec as degraded", func() {
+			// The webhook is not running in the test environment, so the invalid
+			// resource is accepted by the API server.
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					FRRK8SConfig: &metallbv1beta1.FRRK8SConfig{AlwaysBlock: []string{"invalid_cidr"}},
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the degraded condition")
+			Eventually(func() *metav1.Condition {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return nil
+				}
+				return meta.FindStatusCondition(toCheck.Status.Conditions, status.ConditionDegraded)
+			}, 5*time.Second, 200*time.Millisecond).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", reasonInvalidMetalLBSpec),
+				HaveField("Message", ContainSubstring("invalid_cidr")),
+			))
+
+			toCheck := &metallbv1beta1.MetalLB{}
+			err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(toCheck.Status.Conditions, status.ConditionAvailable)).To(BeFalse())
+
+			By("Checking the operands are not applied")
+			err = k8sClient.Get(context.Background(), types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}, &appsv1.DaemonSet{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+		It("Should revert manual changes to the operands", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			speakerDaemonSet := &appsv1.DaemonSet{}
+			speakerKey := types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+
+			By("Editing the speaker daemonset")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				err := k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+				if err != nil {
+					return err
+				}
+				speakerDaemonSet.Spec.Template.Spec.Containers[0].Image = "edited:manually"
+				return k8sClient.Update(context.Background(), speakerDaemonSet)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the change is reverted")
+			Eventually(func() string {
+				err := k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+				if err != nil {
+					return ""
+				}
+				return speakerDaemonSet.Spec.Template.Spec.Containers[0].Image
+			}, 5*time.Second, 200*time.Millisecond).ShouldNot(SatisfyAny(BeEmpty(), Equal("edited:manually")))
+		})
+		It("Should count the reverted manual changes to the operands", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			speakerDaemonSet := &appsv1.DaemonSet{}
+			speakerKey := types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+			corrections := testutil.ToFloat64(driftCorrections.WithLabelValues("DaemonSet"))
+
+			By("Editing the speaker daemonset")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				err := k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+				if err != nil {
+					return err
+				}
+				speakerDaemonSet.Spec.Template.Spec.Containers[0].Image = "edited:manually"
+				return k8sClient.Update(context.Background(), speakerDaemonSet)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the drift correction is counted")
+			Eventually(func() float64 {
+				return testutil.ToFloat64(driftCorrections.WithLabelValues("DaemonSet"))
+			}, 5*time.Second, 200*time.Millisecond).Should(BeNumerically(">", corrections))
+		})
+
+		It("Should not apply the operands while paused", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "metallb",
+					Namespace:   MetalLBTestNameSpace,
+					Annotations: map[string]string{pausedAnnotation: "true"},
+				},
+			}
+
+			By("Creating a paused MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the Paused condition is set")
+			Eventually(func() bool {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(toCheck.Status.Conditions, status.ConditionPaused)
+			}, 2*time.Second, 200*time.Millisecond).Should(BeTrue())
+
+			speakerKey := types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}
+			Consistently(func() bool {
+				err := k8sClient.Get(context.Background(), speakerKey, &appsv1.DaemonSet{})
+				return apierrors.IsNotFound(err)
+			}, 2*time.Second, 200*time.Millisecond).Should(BeTrue())
+
+			By("Resuming the reconciliation")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				toUpdate := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toUpdate)
+				if err != nil {
+					return err
+				}
+				delete(toUpdate.Annotations, pausedAnnotation)
+				return k8sClient.Update(context.Background(), toUpdate)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), speakerKey, &appsv1.DaemonSet{})
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+			Eventually(func() *metav1.Condition {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return &metav1.Condition{}
+				}
+				return meta.FindStatusCondition(toCheck.Status.Conditions, status.ConditionPaused)
+			}, 2*time.Second, 200*time.Millisecond).Should(BeNil())
+		})
+		It("Should report the changes without applying them in preview mode", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					ApplyMode: metallbv1beta1.ApplyModePreview,
+				},
+			}
+
+			By("Creating a MetalLB resource in preview mode")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the changes are reported in the status")
+			Eventually(func() []metallbv1beta1.OperandChange {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toCheck)
+				if err != nil {
+					return nil
+				}
+				return toCheck.Status.PendingChanges
+			}, 2*time.Second, 200*time.Millisecond).Should(ContainElement(metallbv1beta1.OperandChange{
+				Kind:      "DaemonSet",
+				Namespace: MetalLBTestNameSpace,
+				Name:      consts.MetalLBDaemonsetName,
+				Action:    "Create",
+			}))
+
+			By("Checking the operands are not applied")
+			speakerKey := types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}
+			Consistently(func() bool {
+				err := k8sClient.Get(context.Background(), speakerKey, &appsv1.DaemonSet{})
+				return apierrors.IsNotFound(err)
+			}, 2*time.Second, 200*time.Millisecond).Should(BeTrue())
+
+			By("Switching to apply mode")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				toUpdate := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(metallb), toUpdate)
+				if err != nil {
+					return err
+				}
+				toUpdate.Spec.ApplyMode = metallbv1beta1.ApplyModeApply
+				return k8sClient.Update(context.Background(), toUpdate)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), speakerKey, &appsv1.DaemonSet{})
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+		})
+		It("Should not revert changes to unmanaged objects", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			speakerDaemonSet := &appsv1.DaemonSet{}
+			speakerKey := types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+			}, 2*time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+
+			By("Editing the speaker daemonset and marking it as unmanaged")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				err := k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+				if err != nil {
+					return err
+				}
+				if speakerDaemonSet.Annotations == nil {
+					speakerDaemonSet.Annotations = map[string]string{}
+				}
+				speakerDaemonSet.Annotations[unmanagedAnnotation] = "true"
+				speakerDaemonSet.Spec.Template.Spec.Containers[0].Image = "edited:manually"
+				return k8sClient.Update(context.Background(), speakerDaemonSet)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the change is preserved")
+			Consistently(func() string {
+				err := k8sClient.Get(context.Background(), speakerKey, speakerDaemonSet)
+				if err != nil {
+					return ""
+				}
+				return speakerDaemonSet.Spec.Template.Spec.Containers[0].Image
+			}, 2*time.Second, 200*time.Millisecond).Should(Equal("edited:manually"))
+		})
+		It("Should generate and rotate the memberlist key", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					Memberlist: &metallbv1beta1.MemberlistConfig{
+						SecretName:          "memberlist-test",
+						KeyRotationInterval: &metav1.Duration{Duration: time.Hour},
+					},
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret := &v1.Secret{}
+			secretKey := types.NamespacedName{Name: "memberlist-test", Namespace: MetalLBTestNameSpace}
+			Eventually(func() []byte {
+				err := k8sClient.Get(context.Background(), secretKey, secret)
+				if err != nil {
+					return nil
+				}
+				return secret.Data[memberlistSecretKey]
+			}, 2*time.Second, 200*time.Millisecond).Should(HaveLen(memberlistKeySize))
+			key := secret.Data[memberlistSecretKey]
+
+			speakerKeyHash := func() string {
+				speakerDaemonSet := &appsv1.DaemonSet{}
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}, speakerDaemonSet)
+				if err != nil {
+					return ""
+				}
+				return speakerDaemonSet.Spec.Template.Annotations[memberlistKeyHashAnnotation]
+			}
+			Eventually(speakerKeyHash, 2*time.Second, 200*time.Millisecond).ShouldNot(BeEmpty())
+			hash := speakerKeyHash()
+
+			By("Making the key due for rotation")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				err := k8sClient.Get(context.Background(), secretKey, secret)
+				if err != nil {
+					return err
+				}
+				secret.Annotations[memberlistKeyRotatedAtAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+				return k8sClient.Update(context.Background(), secret)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the key is not rotated while the speakers are being rolled out")
+			// There is no daemonset controller in the test environment, so the
+			// speaker status never reports the current generation as rolled out.
+			Consistently(func() []byte {
+				err := k8sClient.Get(context.Background(), secretKey, secret)
+				if err != nil {
+					return nil
+				}
+				return secret.Data[memberlistSecretKey]
+			}, 2*time.Second, 200*time.Millisecond).Should(Equal(key))
+			Expect(speakerKeyHash()).To(Equal(hash))
+
+			By("Completing the rollout of the speakers")
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				speakerDaemonSet := &appsv1.DaemonSet{}
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: consts.MetalLBDaemonsetName, Namespace: MetalLBTestNameSpace}, speakerDaemonSet)
+				if err != nil {
+					return err
+				}
+				speakerDaemonSet.Status.ObservedGeneration = speakerDaemonSet.Generation
+				speakerDaemonSet.Status.DesiredNumberScheduled = 2
+				speakerDaemonSet.Status.UpdatedNumberScheduled = 2
+				speakerDaemonSet.Status.NumberReady = 2
+				return k8sClient.Status().Update(context.Background(), speakerDaemonSet)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Checking the key is rotated and the speakers are rolled out")
+			Eventually(func() []byte {
+				err := k8sClient.Get(context.Background(), secretKey, secret)
+				if err != nil {
+					return nil
+				}
+				return secret.Data[memberlistSecretKey]
+			}, 5*time.Second, 200*time.Millisecond).ShouldNot(SatisfyAny(BeEmpty(), Equal(key)))
+			Eventually(speakerKeyHash, 5*time.Second, 200*time.Millisecond).ShouldNot(SatisfyAny(BeEmpty(), Equal(hash)))
+		})
+		It("Should reject multiple controller replicas", func() {
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					ControllerConfig: &metallbv1beta1.Config{
+						Replicas: ptr.To(int32(2)),
+					},
+				},
+			}
+
+			By("Creating a MetalLB resource")
+			err := k8sClient.Create(context.Background(), metallb)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
 		It("Should switch between modes", func() {
 			checkSpeakerBGPMode := func(mode metallbv1beta1.BGPType) {
 				bgpTypeMatcher := ContainElement(v1.EnvVar{Name: "METALLB_BGP_TYPE", Value: string(mode)})
@@ -407,6 +967,16 @@ var _ = Describe("MetalLB Controller", func() {
 
 			By("Checking the speaker is running in frr mode")
 			checkSpeakerBGPMode(metallbv1beta1.FRRMode)
+
+			By("Checking the backend in effect is reported in the status")
+			Eventually(func() *metallbv1beta1.EffectiveSpec {
+				toCheck := &metallbv1beta1.MetalLB{}
+				err := k8sClient.Get(context.Background(), client.ObjectKey{Name: "metallb", Namespace: MetalLBTestNameSpace}, toCheck)
+				if err != nil {
+					return nil
+				}
+				return toCheck.Status.EffectiveSpec
+			}, 5*time.Second, 200*time.Millisecond).Should(Equal(&metallbv1beta1.EffectiveSpec{BGPBackend: metallbv1beta1.FRRMode}))
 		})
 	})
 })
@@ -416,6 +986,17 @@ func cleanTestNamespace() error {
 	if err != nil {
 		return err
 	}
+	// The finalizer delays the deletion until the cluster scoped resources are removed.
+	err = wait.PollUntilContextTimeout(context.Background(), 200*time.Millisecond, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		metallbs := &metallbv1beta1.MetalLBList{}
+		if err := k8sClient.List(ctx, metallbs, client.InNamespace(MetalLBTestNameSpace)); err != nil {
+			return false, err
+		}
+		return len(metallbs.Items) == 0, nil
+	})
+	if err != nil {
+		return err
+	}
 	err = k8sClient.DeleteAllOf(context.Background(), &appsv1.Deployment{}, client.InNamespace(MetalLBTestNameSpace))
 	if err != nil {
 		return err
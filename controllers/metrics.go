@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/status"
+)
+
+const metricsNamespace = "metallb_operator"
+
+// The reconciliation phases whose duration is observed.
+const (
+	phaseRender = "render"
+	phaseApply  = "apply"
+	phaseStatus = "status"
+)
+
+var (
+	reconcilePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_phase_duration_seconds",
+		Help:      "Duration of the phases of the MetalLB reconciliation: render, apply and status.",
+	}, []string{"phase"})
+
+	appliedObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "applied_objects_total",
+		Help:      "Number of operand objects applied, by kind.",
+	}, []string{"kind"})
+
+	prunedObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pruned_objects_total",
+		Help:      "Number of operand objects deleted because not rendered anymore, by kind.",
+	}, []string{"kind"})
+
+	renderCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "render_cache_hits_total",
+		Help:      "Number of reconciliations that reused the objects rendered for an unchanged MetalLB resource.",
+	})
+
+	bgpBackendInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "bgp_backend_info",
+		Help:      "The BGP backend currently configured, set to 1 for the backend in use.",
+	}, []string{"backend"})
+
+	operandReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "operand_ready",
+		Help:      "Readiness of the operands, by component condition: 1 if ready, 0 otherwise.",
+	}, []string{"condition"})
+
+	driftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_corrections_total",
+		Help:      "Number of operand objects modified outside of the operator and reverted to their rendered state, by kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		reconcilePhaseDuration,
+		appliedObjects,
+		prunedObjects,
+		renderCacheHits,
+		bgpBackendInfo,
+		operandReady,
+		driftCorrections,
+	)
+}
+
+func observePhase(phase string, start time.Time) {
+	reconcilePhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+func setBGPBackendInfo(backend metallbv1beta1.BGPType) {
+	bgpBackendInfo.Reset()
+	bgpBackendInfo.WithLabelValues(string(backend)).Set(1)
+}
+
+// setOperandReadiness replaces the readiness gauges with the given component
+// conditions, so the components not deployed anymore are not reported. The
+// MonitoringUnavailable condition is skipped, as it is True when something is
+// missing rather than ready.
+func setOperandReadiness(components []metav1.Condition) {
+	operandReady.Reset()
+	for _, c := range components {
+		if c.Type == status.ConditionMonitoringUnavailable {
+			continue
+		}
+		ready := 0.0
+		if c.Status == metav1.ConditionTrue {
+			ready = 1
+		}
+		operandReady.WithLabelValues(c.Type).Set(ready)
+	}
+}
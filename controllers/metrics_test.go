@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"github.com/metallb/metallb-operator/pkg/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Metrics", func() {
+	It("Should report the readiness of the operands only", func() {
+		setOperandReadiness([]metav1.Condition{
+			{Type: status.ConditionSpeakerReady, Status: metav1.ConditionTrue},
+			{Type: status.ConditionControllerReady, Status: metav1.ConditionFalse},
+			{Type: status.ConditionMonitoringUnavailable, Status: metav1.ConditionTrue},
+		})
+
+		Expect(testutil.CollectAndCount(operandReady)).To(Equal(2))
+		Expect(testutil.ToFloat64(operandReady.WithLabelValues(status.ConditionSpeakerReady))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(operandReady.WithLabelValues(status.ConditionControllerReady))).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/platform"
+)
+
+const (
+	monitoringGroup        = "monitoring.coreos.com"
+	monitoringGroupVersion = monitoringGroup + "/v1"
+)
+
+func monitoringObject(kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: monitoringGroup, Version: "v1", Kind: kind})
+	return obj
+}
+
+// withoutUnavailableMonitoring drops the rendered monitoring objects whose CRDs
+// are not installed, returning the kinds that were dropped. The kinds that are
+// installed are watched as the other operands.
+func (r *MetalLBReconciler) withoutUnavailableMonitoring(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, []string, error) {
+	if !slices.ContainsFunc(objs, isMonitoringObject) {
+		return objs, nil, nil
+	}
+	served, err := platform.ServedKinds(r.discovery, monitoringGroupVersion)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to discover the %s kinds", monitoringGroupVersion)
+	}
+
+	res := []*unstructured.Unstructured{}
+	missing := []string{}
+	for _, obj := range objs {
+		if !isMonitoringObject(obj) {
+			res = append(res, obj)
+			continue
+		}
+		kind := obj.GetKind()
+		if !served[kind] {
+			if !slices.Contains(missing, kind) {
+				missing = append(missing, kind)
+			}
+			continue
+		}
+		if err := r.watchMonitoringKind(kind); err != nil {
+			return nil, nil, err
+		}
+		res = append(res, obj)
+	}
+	sort.Strings(missing)
+	return res, missing, nil
+}
+
+func isMonitoringObject(obj *unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == monitoringGroupVersion
+}
+
+// watchMonitoringKind starts watching the objects of the given monitoring kind
+// owned by the MetalLB resource, unless they are watched already. The watches
+// are added only once the kind is served, as the informers of kinds whose CRDs
+// are not installed never sync.
+func (r *MetalLBReconciler) watchMonitoringKind(kind string) error {
+	r.monitoringWatchesLock.Lock()
+	defer r.monitoringWatchesLock.Unlock()
+	if r.controller == nil || r.monitoringWatches[kind] {
+		return nil
+	}
+	src := source.Kind[client.Object](r.cache, monitoringObject(kind),
+		handler.EnqueueRequestForOwner(r.Scheme, r.RESTMapper(), &metallbv1beta1.MetalLB{}, handler.OnlyControllerOwner()),
+		desiredStateChanged())
+	if err := r.controller.Watch(src); err != nil {
+		return errors.Wrapf(err, "failed to watch %s", kind)
+	}
+	r.monitoringWatches[kind] = true
+	return nil
+}
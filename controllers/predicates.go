@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// desiredStateChanged filters out the updates touching only the status or the
+// metadata fields maintained by the apiserver, so that the reconciler reacts
+// only to the changes that may need to be reverted.
+func desiredStateChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldObj, err := desiredState(e.ObjectOld)
+			if err != nil {
+				return true
+			}
+			newObj, err := desiredState(e.ObjectNew)
+			if err != nil {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(oldObj, newObj)
+		},
+	}
+}
+
+func desiredState(obj client.Object) (map[string]interface{}, error) {
+	res, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(res, "status")
+	if metadata, ok := res["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+		delete(metadata, "managedFields")
+		delete(metadata, "generation")
+	}
+	return res, nil
+}
+
+// workloadReadinessChanged passes the updates changing the fields the
+// readiness of the operands is computed from.
+func workloadReadinessChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			switch oldObj := e.ObjectOld.(type) {
+			case *appsv1.DaemonSet:
+				newObj, ok := e.ObjectNew.(*appsv1.DaemonSet)
+				if !ok {
+					return true
+				}
+				return oldObj.Status.ObservedGeneration != newObj.Status.ObservedGeneration ||
+					oldObj.Status.DesiredNumberScheduled != newObj.Status.DesiredNumberScheduled ||
+					oldObj.Status.CurrentNumberScheduled != newObj.Status.CurrentNumberScheduled ||
+					oldObj.Status.NumberReady != newObj.Status.NumberReady ||
+					oldObj.Status.UpdatedNumberScheduled != newObj.Status.UpdatedNumberScheduled
+			case *appsv1.Deployment:
+				newObj, ok := e.ObjectNew.(*appsv1.Deployment)
+				if !ok {
+					return true
+				}
+				return oldObj.Status.ObservedGeneration != newObj.Status.ObservedGeneration ||
+					oldObj.Status.ReadyReplicas != newObj.Status.ReadyReplicas ||
+					oldObj.Status.UpdatedReplicas != newObj.Status.UpdatedReplicas
+			}
+			return false
+		},
+	}
+}
+
+// endpointsReadinessChanged passes the updates changing the readiness of the
+// endpoints of a service.
+func endpointsReadinessChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldObj, ok := e.ObjectOld.(*discoveryv1.EndpointSlice)
+			if !ok {
+				return true
+			}
+			newObj, ok := e.ObjectNew.(*discoveryv1.EndpointSlice)
+			if !ok {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(oldObj.Endpoints, newObj.Endpoints)
+		},
+	}
+}
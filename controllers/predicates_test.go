@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Predicates", func() {
+	It("Should ignore status only updates", func() {
+		oldObj := &appsv1.DaemonSet{}
+		oldObj.Name = "speaker"
+		oldObj.ResourceVersion = "1"
+
+		newObj := oldObj.DeepCopy()
+		newObj.ResourceVersion = "2"
+		newObj.Status.NumberMisscheduled = 1
+		Expect(desiredStateChanged().Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj})).To(BeFalse())
+		Expect(workloadReadinessChanged().Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj})).To(BeFalse())
+
+		newObj.Status.NumberReady = 1
+		Expect(workloadReadinessChanged().Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj})).To(BeTrue())
+	})
+
+	It("Should pass spec updates", func() {
+		oldObj := &appsv1.DaemonSet{}
+		oldObj.Name = "speaker"
+
+		newObj := oldObj.DeepCopy()
+		newObj.Spec.Template.Spec.Containers = []corev1.Container{{Name: "speaker", Image: "changed"}}
+		Expect(desiredStateChanged().Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj})).To(BeTrue())
+	})
+})
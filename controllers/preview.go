@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+)
+
+const (
+	changeActionCreate = "Create"
+	changeActionUpdate = "Update"
+	changeActionDelete = "Delete"
+)
+
+// maxChangedFieldDepth limits the depth of the reported changed fields, so
+// that the status stays readable.
+const maxChangedFieldDepth = 4
+
+// previewMetalLBResources renders the operands and returns the changes applying
+// them would cause, computed via server side apply dry runs.
+func (r *MetalLBReconciler) previewMetalLBResources(ctx context.Context, config *metallbv1beta1.MetalLB) ([]metallbv1beta1.OperandChange, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateBGPMode(config, r.EnvConfig.IsOpenshift); err != nil {
+		return nil, err
+	}
+	objs, toDel, err := r.renderCache.render(r.metalLBChart, r.frrk8sChart, r.EnvConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	objs, err = r.withMemberlistSecret(ctx, config, objs)
+	if err != nil {
+		return nil, err
+	}
+	objs, _, err = r.withoutUnavailableMonitoring(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []metallbv1beta1.OperandChange{}
+	for _, obj := range toDel {
+		existing, err := r.existingObject(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			changes = append(changes, operandChange(existing, changeActionDelete, nil))
+		}
+	}
+
+	for _, obj := range objs {
+		existing, err := r.existingObject(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.GetAnnotations()[unmanagedAnnotation] == "true" {
+			continue
+		}
+		dryRun := obj.DeepCopy()
+		if err := r.apply(ctx, config, dryRun, client.DryRunAll); err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			changes = append(changes, operandChange(obj, changeActionCreate, nil))
+			continue
+		}
+		fields, err := changedFields(existing, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, operandChange(obj, changeActionUpdate, fields))
+		}
+	}
+
+	toPrune, err := r.pruneCandidates(ctx, objs)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range toPrune {
+		changes = append(changes, operandChange(obj, changeActionDelete, nil))
+	}
+	return changes, nil
+}
+
+func operandChange(obj *unstructured.Unstructured, action string, fields []string) metallbv1beta1.OperandChange {
+	return metallbv1beta1.OperandChange{
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Action:    action,
+		Fields:    fields,
+	}
+}
+
+// changedFields returns the sorted paths of the fields that differ between the
+// two versions of the object, ignoring the status and the metadata maintained
+// by the apiserver.
+func changedFields(oldObj, newObj *unstructured.Unstructured) ([]string, error) {
+	oldState, err := desiredState(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newState, err := desiredState(newObj)
+	if err != nil {
+		return nil, err
+	}
+	res := diffPaths(oldState, newState, nil)
+	sort.Strings(res)
+	return res, nil
+}
+
+func diffPaths(oldValue, newValue interface{}, path []string) []string {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if !oldIsMap || !newIsMap || len(path) == maxChangedFieldDepth {
+		if reflect.DeepEqual(oldValue, newValue) {
+			return nil
+		}
+		return []string{strings.Join(path, ".")}
+	}
+
+	res := []string{}
+	keys := map[string]bool{}
+	for k := range oldMap {
+		keys[k] = true
+	}
+	for k := range newMap {
+		keys[k] = true
+	}
+	for k := range keys {
+		res = append(res, diffPaths(oldMap[k], newMap[k], append(path[:len(path):len(path)], k))...)
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Preview", func() {
+	It("Should report the changed fields", func() {
+		oldObj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "DaemonSet",
+			"metadata": map[string]interface{}{
+				"name":            "speaker",
+				"resourceVersion": "1",
+				"labels":          map[string]interface{}{"app": "metallb"},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"nodeSelector": map[string]interface{}{"a": "b"},
+						"containers":   []interface{}{map[string]interface{}{"name": "speaker", "image": "old"}},
+					},
+				},
+			},
+			"status": map[string]interface{}{"numberReady": int64(1)},
+		}}
+		newObj := oldObj.DeepCopy()
+		newObj.SetResourceVersion("2")
+		Expect(unstructured.SetNestedField(newObj.Object, int64(2), "status", "numberReady")).To(Succeed())
+		fields, err := changedFields(oldObj, newObj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(BeEmpty())
+
+		Expect(unstructured.SetNestedField(newObj.Object, "c", "spec", "template", "spec", "nodeSelector", "a")).To(Succeed())
+		Expect(unstructured.SetNestedSlice(newObj.Object, []interface{}{map[string]interface{}{"name": "speaker", "image": "new"}}, "spec", "template", "spec", "containers")).To(Succeed())
+		newObj.SetLabels(map[string]string{"app": "metallb", "new": "label"})
+		fields, err = changedFields(oldObj, newObj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(Equal([]string{"metadata.labels.new", "spec.template.spec.containers", "spec.template.spec.nodeSelector"}))
+	})
+})
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/helm"
+	"github.com/metallb/metallb-operator/pkg/params"
+)
+
+// Render returns the objects the operator would apply for the given MetalLB
+// resource, without accessing the cluster. The charts are loaded from
+// MetalLBChartPath and FRRK8SChartPath. The memberlist key is generated by the
+// operator, so the memberlist Secret and the speaker annotation with the hash
+// of the key are rendered with a placeholder key.
+func Render(envConfig params.EnvConfig, config *metallbv1beta1.MetalLB) ([]*unstructured.Unstructured, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateBGPMode(config, envConfig.IsOpenshift); err != nil {
+		return nil, err
+	}
+
+	objs, err := renderFromChartPaths(envConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	if !params.MemberlistEnabled(config) {
+		return objs, nil
+	}
+	secret := newMemberlistSecret(config, envConfig.Namespace)
+	secret.Data = map[string][]byte{memberlistSecretKey: []byte(memberlistPlaceholderKey)}
+	return withMemberlistKey(objs, secret)
+}
+
+// ValidateOverrides checks that the overrides of the given MetalLB resource
+// apply to the objects rendered for it. It is meant to be used by the
+// validating webhook.
+func ValidateOverrides(envConfig params.EnvConfig, config *metallbv1beta1.MetalLB) error {
+	if len(config.Spec.Overrides) == 0 {
+		return nil
+	}
+	_, err := renderFromChartPaths(envConfig, config)
+	return err
+}
+
+func renderFromChartPaths(envConfig params.EnvConfig, config *metallbv1beta1.MetalLB) ([]*unstructured.Unstructured, error) {
+	metalLBChart, err := helm.NewMetalLBChart(MetalLBChartPath, defaultMetalLBCrName, envConfig.Namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	frrk8sChart, err := helm.NewFRRK8SChart(FRRK8SChartPath, "frr-k8s", envConfig.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	objs, _, err := renderObjects(metalLBChart, frrk8sChart, envConfig, config)
+	return objs, err
+}
+
+// renderObjects renders the operands for the given MetalLB resource, returning
+// the objects to apply and the frr-k8s ones to delete when frr-k8s is not
+// deployed by the operator.
+func renderObjects(metalLBChart *helm.MetalLBChart, frrk8sChart *helm.FRRK8SChart, envConfig params.EnvConfig, config *metallbv1beta1.MetalLB) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	rendered := []*unstructured.Unstructured{}
+	toDel := []*unstructured.Unstructured{}
+	frrk8sObjs, err := frrk8sChart.Objects(envConfig, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if params.BGPType(config, envConfig) == metallbv1beta1.FRRK8sMode {
+		rendered = append(rendered, frrk8sObjs...)
+	} else {
+		toDel = append(toDel, frrk8sObjs...)
+	}
+
+	mlbObjs, err := metalLBChart.Objects(envConfig, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	rendered = append(rendered, mlbObjs...)
+
+	objs := []*unstructured.Unstructured{}
+	for _, obj := range rendered {
+		objKind := obj.GetKind()
+		// Skip applying role and role binding object, because with the operator these are being set outside,
+		// either in manifests or via the csv.
+		if objKind == "Role" || objKind == "RoleBinding" {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	if err := helm.ApplyOverrides(objs, config.Spec.Overrides); err != nil {
+		return nil, nil, err
+	}
+	for _, obj := range objs {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[managedByLabel] = fieldManager
+		obj.SetLabels(labels)
+	}
+	return objs, toDel, nil
+}
+
+// renderCache holds the objects rendered for the last seen MetalLB resource, so
+// the charts are rendered again only when the resource changes.
+type renderCache struct {
+	lock  sync.Mutex
+	key   string
+	objs  []*unstructured.Unstructured
+	toDel []*unstructured.Unstructured
+}
+
+// render returns the result of renderObjects for the given MetalLB resource,
+// reusing the cached one when the resource did not change. The returned objects
+// are copies that can be modified by the caller.
+func (c *renderCache) render(metalLBChart *helm.MetalLBChart, frrk8sChart *helm.FRRK8SChart, envConfig params.EnvConfig, config *metallbv1beta1.MetalLB) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	key, err := renderKey(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.key == key {
+		renderCacheHits.Inc()
+		return deepCopyObjects(c.objs), deepCopyObjects(c.toDel), nil
+	}
+	objs, toDel, err := renderObjects(metalLBChart, frrk8sChart, envConfig, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.key, c.objs, c.toDel = key, deepCopyObjects(objs), deepCopyObjects(toDel)
+	return objs, toDel, nil
+}
+
+// renderKey identifies the inputs of the rendering coming from the MetalLB
+// resource. The env config is not part of it as it does not change during the
+// lifetime of the operator.
+func renderKey(config *metallbv1beta1.MetalLB) (string, error) {
+	data, err := json.Marshal(struct {
+		Namespace string
+		Name      string
+		Spec      metallbv1beta1.MetalLBSpec
+	}{config.Namespace, config.Name, config.Spec})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func deepCopyObjects(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	res := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, obj.DeepCopy())
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/pkg/helm"
+	"github.com/metallb/metallb-operator/test/consts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Render", func() {
+	kindName := func(obj *unstructured.Unstructured) string {
+		return obj.GetKind() + "/" + obj.GetName()
+	}
+
+	DescribeTable("Should render the objects to apply", func(bgpType metallbv1beta1.BGPType, withFRRK8s bool) {
+		metallb := &metallbv1beta1.MetalLB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metallb",
+				Namespace: MetalLBTestNameSpace,
+			},
+			Spec: metallbv1beta1.MetalLBSpec{
+				BGPBackend: bgpType,
+			},
+		}
+		objs, err := Render(defaultEnvConfig, metallb)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(objs).To(ContainElement(WithTransform(kindName, Equal("DaemonSet/"+consts.MetalLBDaemonsetName))))
+		Expect(objs).ToNot(ContainElement(WithTransform(kindName, HavePrefix("Role/"))))
+		frrk8sMatcher := ContainElement(WithTransform(kindName, Equal("DaemonSet/"+consts.FRRK8SDaemonsetName)))
+		if withFRRK8s {
+			Expect(objs).To(frrk8sMatcher)
+		} else {
+			Expect(objs).ToNot(frrk8sMatcher)
+		}
+		for _, obj := range objs {
+			Expect(obj.GetLabels()).To(HaveKeyWithValue(managedByLabel, fieldManager))
+		}
+
+		Expect(objs).To(ContainElement(WithTransform(kindName, Equal("Secret/metallb-memberlist"))))
+		for _, obj := range objs {
+			if kindName(obj) != "DaemonSet/"+consts.MetalLBDaemonsetName {
+				continue
+			}
+			annotations, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(annotations).To(HaveKey(memberlistKeyHashAnnotation))
+		}
+	},
+		Entry("frr mode", metallbv1beta1.FRRMode, false),
+		Entry("frr-k8s mode", metallbv1beta1.FRRK8sMode, true),
+	)
+
+	It("Should reuse the objects rendered for an unchanged resource", func() {
+		metalLBChart, err := helm.NewMetalLBChart(MetalLBChartPath, defaultMetalLBCrName, MetalLBTestNameSpace, nil)
+		Expect(err).ToNot(HaveOccurred())
+		frrk8sChart, err := helm.NewFRRK8SChart(FRRK8SChartPath, "frr-k8s", MetalLBTestNameSpace)
+		Expect(err).ToNot(HaveOccurred())
+		metallb := &metallbv1beta1.MetalLB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metallb",
+				Namespace: MetalLBTestNameSpace,
+			},
+		}
+		cache := &renderCache{}
+		hits := testutil.ToFloat64(renderCacheHits)
+
+		objs, _, err := cache.render(metalLBChart, frrk8sChart, defaultEnvConfig, metallb)
+		Expect(err).ToNot(HaveOccurred())
+		name := objs[0].GetName()
+		objs[0].SetName("changed")
+
+		cached, _, err := cache.render(metalLBChart, frrk8sChart, defaultEnvConfig, metallb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(renderCacheHits)).To(Equal(hits + 1))
+		Expect(cached[0].GetName()).To(Equal(name))
+
+		metallb.Spec.LogLevel = metallbv1beta1.LogLevelDebug
+		_, _, err = cache.render(metalLBChart, frrk8sChart, defaultEnvConfig, metallb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(renderCacheHits)).To(Equal(hits + 1))
+	})
+})
@@ -28,6 +28,8 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -68,10 +70,11 @@ const (
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs="*"
 
 var (
-	scheme            = runtime.NewScheme()
-	setupLog          = ctrl.Log.WithName("setup")
-	webhookName       = "metallb-operator-webhook-configuration"
-	webhookSecretName = "metallb-operator-webhook-server-cert"
+	scheme              = runtime.NewScheme()
+	setupLog            = ctrl.Log.WithName("setup")
+	webhookName         = "metallb-operator-webhook-configuration"
+	mutatingWebhookName = "metallb-operator-mutating-webhook-configuration"
+	webhookSecretName   = "metallb-operator-webhook-server-cert"
 )
 
 func init() {
@@ -93,6 +96,14 @@ func init() {
 var build = "develop"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		if err := render(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var (
 		metricsAddr          = flag.String("metrics-addr", "0", "The address the metric endpoint binds to.")
 		enableLeaderElection = flag.Bool("enable-leader-election", false, "Enable leader election for controller manager. "+
@@ -182,7 +193,12 @@ func main() {
 		LeaderElectionID: "metallb.io.metallboperator",
 		Cache: cache.Options{
 			ByObject: map[client.Object]cache.ByObject{
-				&metallbv1beta1.MetalLB{}: namespaceSelector,
+				&metallbv1beta1.MetalLB{}:     namespaceSelector,
+				&discoveryv1.EndpointSlice{}:  namespaceSelector,
+				&corev1.Service{}:             namespaceSelector,
+				&networkingv1.NetworkPolicy{}: namespaceSelector,
+				&corev1.Secret{}:              namespaceSelector,
+				&corev1.Pod{}:                 namespaceSelector,
 			},
 		},
 		WebhookServer: webhookServer(9443, *withWebhookHTTP2, tlsOpt),
@@ -216,6 +232,9 @@ func main() {
 		setupLog.Info("waiting to create operator webhook for MetalLB CR")
 		<-setupFinished
 		setupLog.Info("creating operator webhook for MetalLB CR")
+		metallbv1beta1.OverridesValidator = func(metallb *metallbv1beta1.MetalLB) error {
+			return controllers.ValidateOverrides(envParams, metallb)
+		}
 		if err = (&metallbv1beta1.MetalLB{}).SetupWebhookWithManager(mgr, envParams.FRRK8sExternalNamespace); err != nil {
 			setupLog.Error(err, "unable to create webhook", "operator webhook", "MetalLB")
 			os.Exit(1)
@@ -241,6 +260,10 @@ func main() {
 				Name: webhookName,
 				Type: rotator.Validating,
 			},
+			{
+				Name: mutatingWebhookName,
+				Type: rotator.Mutating,
+			},
 		}
 		err = rotator.AddRotator(mgr, &rotator.CertRotator{
 			SecretKey: types.NamespacedName{
@@ -24,8 +24,11 @@ import (
 
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
 	"github.com/metallb/metallb-operator/pkg/openshift"
+	"github.com/metallb/metallb-operator/pkg/params"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
@@ -80,6 +83,164 @@ func logLevelValue(crdConfig *metallbv1beta1.MetalLB) metallbv1beta1.MetalLBLogL
 	return metallbv1beta1.LogLevelInfo
 }
 
+// bgpDebounceTimeoutValue returns the debounce timeout in milliseconds as
+// expected by the charts, or nil to use the components' default.
+func bgpDebounceTimeoutValue(crdConfig *metallbv1beta1.MetalLB) interface{} {
+	if crdConfig.Spec.BGPDebounceTimeout == nil {
+		return nil
+	}
+	return crdConfig.Spec.BGPDebounceTimeout.Milliseconds()
+}
+
+// overrideContainerResources sets the resources of the containers found in the
+// given map, keyed by container name.
+func overrideContainerResources(containers []corev1.Container, resources map[string]corev1.ResourceRequirements) {
+	for i := range containers {
+		if r, ok := resources[containers[i].Name]; ok {
+			containers[i].Resources = r
+		}
+	}
+}
+
+// overrideImageDigests pins the images of the containers of the given workload
+// to the digests the operator is configured with. This is needed because the
+// charts only support tags.
+func overrideImageDigests(envConfig params.EnvConfig, obj *unstructured.Unstructured) error {
+	images := []params.ImageInfo{envConfig.ControllerImage, envConfig.SpeakerImage, envConfig.FRRImage, envConfig.FRRK8sImage}
+	return overrideContainerImages(obj, func(image string) string {
+		for _, i := range images {
+			if i.Digest != "" && i.Repo != "" && strings.HasPrefix(image, i.Repo+":") {
+				return i.Reference()
+			}
+		}
+		return image
+	})
+}
+
+// overrideImageRegistry replaces the registry of the images of the containers
+// of the given workload with the one set in the MetalLB resource.
+func overrideImageRegistry(crdConfig *metallbv1beta1.MetalLB, obj *unstructured.Unstructured) error {
+	registry := crdConfig.Spec.ImageRegistry
+	if registry == "" {
+		return nil
+	}
+	return overrideContainerImages(obj, func(image string) string {
+		return withImageRegistry(image, registry)
+	})
+}
+
+// overrideContainerImages replaces the image of each container of the given
+// workload with the one returned by the override function.
+func overrideContainerImages(obj *unstructured.Unstructured, override func(string) string) error {
+	if obj.GetKind() != "Deployment" && obj.GetKind() != "DaemonSet" {
+		return nil
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, ok := container["image"].(string); ok {
+				container["image"] = override(image)
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withImageRegistry returns the image pulled from the given registry. As
+// docker does, the first component of the image is the registry only if it
+// contains a dot or a port, or is localhost.
+func withImageRegistry(image, registry string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image = parts[1]
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+func monitorLabelsValue(crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
+	if crdConfig.Spec.Monitoring == nil {
+		return map[string]interface{}{}
+	}
+	return toInterfaceMap(crdConfig.Spec.Monitoring.AdditionalLabels)
+}
+
+// overrideMonitorEndpoints sets the scrape interval and appends the metric
+// relabelings to the endpoints of the given monitor. This is done after
+// rendering because the charts accept only integer intervals, which Prometheus
+// doesn't, and don't set the relabelings on all the endpoints.
+func overrideMonitorEndpoints(crdConfig *metallbv1beta1.MetalLB, obj *unstructured.Unstructured) error {
+	config := crdConfig.Spec.Monitoring
+	if config == nil || (config.Interval == nil && len(config.MetricRelabelings) == 0) {
+		return nil
+	}
+	endpointsField := ""
+	switch obj.GetKind() {
+	case "ServiceMonitor":
+		endpointsField = "endpoints"
+	case "PodMonitor":
+		endpointsField = "podMetricsEndpoints"
+	default:
+		return nil
+	}
+	endpoints, found, err := unstructured.NestedSlice(obj.Object, "spec", endpointsField)
+	if err != nil || !found {
+		return err
+	}
+	relabelings := []interface{}{}
+	for _, r := range config.MetricRelabelings {
+		relabeling, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&r)
+		if err != nil {
+			return err
+		}
+		relabelings = append(relabelings, relabeling)
+	}
+	for _, e := range endpoints {
+		endpoint, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if config.Interval != nil {
+			endpoint["interval"] = fmt.Sprintf("%ds", int64(config.Interval.Seconds()))
+		}
+		if len(relabelings) > 0 {
+			existing, _, err := unstructured.NestedSlice(endpoint, "metricRelabelings")
+			if err != nil {
+				return err
+			}
+			endpoint["metricRelabelings"] = append(existing, relabelings...)
+		}
+	}
+	return unstructured.SetNestedSlice(obj.Object, endpoints, "spec", endpointsField)
+}
+
+func alertsConfig(crdConfig *metallbv1beta1.MetalLB) *metallbv1beta1.AlertsConfig {
+	if crdConfig.Spec.Monitoring == nil {
+		return nil
+	}
+	return crdConfig.Spec.Monitoring.Alerts
+}
+
+func excludeInterfacesEnabled(crdConfig *metallbv1beta1.MetalLB) bool {
+	excludeConfig := crdConfig.Spec.ExcludeInterfaces
+	if excludeConfig == nil || excludeConfig.Enabled == nil {
+		return true
+	}
+	return *excludeConfig.Enabled
+}
+
 func toInterfaceMap(m map[string]string) map[string]interface{} {
 	result := make(map[string]interface{})
 	for k, v := range m {
@@ -51,3 +51,42 @@ func TestGetImageNameTag(t *testing.T) {
 		g.Expect(tag).To(Equal(test.expectedTag))
 	}
 }
+
+func TestWithImageRegistry(t *testing.T) {
+	tests := []struct {
+		image    string
+		registry string
+		expected string
+	}{
+		{
+			image:    "quay.io/metallb/speaker:v0.13.9",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/metallb/speaker:v0.13.9",
+		},
+		{
+			image:    "quay.io:5000/metallb/speaker:v0.13.9",
+			registry: "mirror.example.com:5000/metallb/",
+			expected: "mirror.example.com:5000/metallb/metallb/speaker:v0.13.9",
+		},
+		{
+			image:    "localhost/metallb/speaker@sha256:0123",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/metallb/speaker@sha256:0123",
+		},
+		{
+			image:    "frrouting/frr:v7.5.1",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/frrouting/frr:v7.5.1",
+		},
+		{
+			image:    "speaker",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/speaker",
+		},
+	}
+
+	g := NewGomegaWithT(t)
+	for _, test := range tests {
+		g.Expect(withImageRegistry(test.image, test.registry)).To(Equal(test.expected))
+	}
+}
@@ -26,6 +26,10 @@ const (
 	frrk8sWebhookSecretName           = "frr-k8s-webhook-server-cert"
 	frrk8sValidatingWebhookName       = "frr-k8s-validating-webhook-configuration"
 	frrk8sCertsSecret                 = "frr-k8s-certs-secret"
+	frrk8sPrometheusRuleName          = "frr-k8s"
+
+	frrk8sBGPSessionDownAlert = "FRRK8sBGPSessionDown"
+	frrk8sBFDSessionDownAlert = "FRRK8sBFDSessionDown"
 )
 
 // FRRK8SChart contains references which helps to retrieve manifest
@@ -88,13 +92,23 @@ func (h *FRRK8SChart) Objects(envConfig params.EnvConfig, crdConfig *metallbv1be
 			obj.SetNamespace(envConfig.Namespace)
 		}
 
-		if isFRRK8SDaemonset(obj) && crdConfig.Spec.SpeakerConfig != nil {
+		if isFRRK8SDaemonset(obj) && (crdConfig.Spec.SpeakerConfig != nil || crdConfig.Spec.FRRK8SConfig != nil) {
 			obj, err = overrideFRRK8SDaemonParameters(crdConfig, obj)
 			if err != nil {
 				return nil, err
 			}
 		}
 
+		if err := overrideMonitorEndpoints(crdConfig, obj); err != nil {
+			return nil, err
+		}
+		if err := overrideImageDigests(envConfig, obj); err != nil {
+			return nil, err
+		}
+		if err := overrideImageRegistry(crdConfig, obj); err != nil {
+			return nil, err
+		}
+
 		if isFRRK8SWebhookSecret(obj) && envConfig.IsOpenshift {
 			// We want to skip creating the secret on OpenShift since it is created and managed
 			// via the serving-cert-secret-name annotation on the service.
@@ -135,17 +149,90 @@ func (h *FRRK8SChart) Objects(envConfig params.EnvConfig, crdConfig *metallbv1be
 
 		res = append(res, obj)
 	}
+	// The alerts rely on the metrics renamed by the ServiceMonitor, and would
+	// never fire with the other kinds of monitors.
+	if params.AlertsEnabled(crdConfig, envConfig) && params.ServiceMonitorsEnabled(crdConfig, envConfig) {
+		res = append(res, frrk8sPrometheusRule(envConfig, crdConfig))
+	}
 	return res, nil
 }
 
+// frrk8sPrometheusRule returns the PrometheusRule with the frr-k8s BGP and BFD
+// session alerts, which the chart doesn't provide. The frr-k8s metrics are
+// renamed with the metallb prefix by the ServiceMonitor.
+func frrk8sPrometheusRule(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) *unstructured.Unstructured {
+	severities := map[string]string{
+		frrk8sBGPSessionDownAlert: "critical",
+		frrk8sBFDSessionDownAlert: "critical",
+	}
+	labels := map[string]interface{}{
+		"app.kubernetes.io/component": "frr-k8s",
+	}
+	if alerts := alertsConfig(crdConfig); alerts != nil {
+		for alert, severity := range alerts.Severities {
+			if _, ok := severities[alert]; ok {
+				severities[alert] = severity
+			}
+		}
+		for k, v := range alerts.AdditionalLabels {
+			labels[k] = v
+		}
+	}
+	rule := func(alert, summary, description, expr string) interface{} {
+		return map[string]interface{}{
+			"alert": alert,
+			"annotations": map[string]interface{}{
+				"summary":     summary,
+				"description": description,
+			},
+			"expr": expr,
+			"for":  "1m",
+			"labels": map[string]interface{}{
+				"severity": severities[alert],
+			},
+		}
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "PrometheusRule",
+			"metadata": map[string]interface{}{
+				"name":      frrk8sPrometheusRuleName,
+				"namespace": envConfig.Namespace,
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name": "frr-k8s.rules",
+						"rules": []interface{}{
+							rule(frrk8sBGPSessionDownAlert,
+								"BGP session down on {{ $labels.pod }}",
+								"frr-k8s on {{ $labels.pod }} has BGP session {{ $labels.peer }} down for > 1 minute",
+								`metallb_bgp_session_up{job=~"frr-k8s.*"} == 0`),
+							rule(frrk8sBFDSessionDownAlert,
+								"BFD session down on {{ $labels.pod }}",
+								"frr-k8s on {{ $labels.pod }} has BFD session {{ $labels.peer }} down for > 1 minute",
+								`metallb_bfd_session_up{job=~"frr-k8s.*"} == 0`),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func patchChartValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB, valuesMap map[string]interface{}) error {
 	var err error
 	valuesMap["frrk8s"], err = frrk8sValues(envConfig, crdConfig)
 	if err != nil {
 		return err
 	}
-	valuesMap["prometheus"] = prometheusValues(envConfig)
+	valuesMap["prometheus"] = prometheusValues(envConfig, crdConfig)
 	valuesMap["tls"] = frrk8sTLSHelmValues(envConfig)
+	if len(crdConfig.Spec.ImagePullSecrets) > 0 {
+		valuesMap["imagePullSecrets"] = crdConfig.Spec.ImagePullSecrets
+	}
 	return nil
 }
 
@@ -183,6 +270,7 @@ func frrk8sValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB)
 		}
 	}
 	frrk8sValueMap["logLevel"] = logLevelValue(crdConfig)
+	frrk8sValueMap["bgpDebounceTimeout"] = bgpDebounceTimeoutValue(crdConfig)
 	frrk8sValueMap["restartOnRotatorSecretRefresh"] = true
 
 	if envConfig.IsOpenshift {
@@ -192,7 +280,7 @@ func frrk8sValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB)
 	}
 
 	// Mirror the behaviour of the speaker pods as frrk8s pods must follow the
-	// speaker pods.
+	// speaker pods, unless configured otherwise.
 	if crdConfig.Spec.SpeakerNodeSelector != nil {
 		frrk8sValueMap["nodeSelector"] = toInterfaceMap(crdConfig.Spec.SpeakerNodeSelector)
 	}
@@ -205,6 +293,16 @@ func frrk8sValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB)
 	}
 
 	if crdConfig.Spec.FRRK8SConfig != nil {
+		frrk8sConfig := crdConfig.Spec.FRRK8SConfig
+		if frrk8sConfig.NodeSelector != nil {
+			frrk8sValueMap["nodeSelector"] = toInterfaceMap(frrk8sConfig.NodeSelector)
+		}
+		if frrk8sConfig.Tolerations != nil {
+			frrk8sValueMap["tolerations"] = frrk8sConfig.Tolerations
+		}
+		if frrk8sConfig.PriorityClassName != "" {
+			frrk8sValueMap["priorityClassName"] = frrk8sConfig.PriorityClassName
+		}
 		var err error
 		frrk8sValueMap["alwaysBlock"], err = alwaysBlockToString(crdConfig.Spec.FRRK8SConfig.AlwaysBlock)
 		if err != nil {
@@ -214,7 +312,7 @@ func frrk8sValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB)
 	return frrk8sValueMap, nil
 }
 
-func prometheusValues(envConfig params.EnvConfig) map[string]interface{} {
+func prometheusValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
 	tlsConfig := map[string]interface{}{
 		"insecureSkipVerify": true,
 	}
@@ -231,8 +329,9 @@ func prometheusValues(envConfig params.EnvConfig) map[string]interface{} {
 		"tlsConfig":   tlsConfig,
 	}
 
-	if envConfig.DeployServiceMonitors {
+	if params.ServiceMonitorsEnabled(crdConfig, envConfig) {
 		serviceMonitor["enabled"] = true
+		serviceMonitor["additionalLabels"] = monitorLabelsValue(crdConfig)
 		serviceMonitor["metricRelabelings"] = []map[string]interface{}{
 			{
 				"regex":        "frrk8s_bgp_(.*)",
@@ -257,20 +356,47 @@ func prometheusValues(envConfig params.EnvConfig) map[string]interface{} {
 	}
 }
 
+// overrideFRRK8SDaemonParameters mirrors the speaker configuration onto the
+// frr-k8s daemonset, and then applies the frr-k8s specific one on top. The
+// per container resources of the speaker are applied last, as they take
+// precedence over the per daemonset ones.
 func overrideFRRK8SDaemonParameters(crdConfig *metallbv1beta1.MetalLB, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
-	config := crdConfig.Spec.SpeakerConfig
 	var daemon *appsv1.DaemonSet
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &daemon)
 	if err != nil {
 		return nil, err
 	}
-	if config.Affinity != nil {
-		daemon.Spec.Template.Spec.Affinity = config.Affinity
+	if config := crdConfig.Spec.SpeakerConfig; config != nil {
+		if config.Affinity != nil {
+			daemon.Spec.Template.Spec.Affinity = config.Affinity
+		}
+		if config.UpdateStrategy != nil {
+			daemon.Spec.UpdateStrategy = *config.UpdateStrategy
+		}
+		for j, container := range daemon.Spec.Template.Spec.Containers {
+			if container.Name == "controller" && config.Resources != nil {
+				daemon.Spec.Template.Spec.Containers[j].Resources = *config.Resources
+			}
+		}
 	}
-	for j, container := range daemon.Spec.Template.Spec.Containers {
-		if container.Name == "controller" && config.Resources != nil {
-			daemon.Spec.Template.Spec.Containers[j].Resources = *config.Resources
+	if config := crdConfig.Spec.FRRK8SConfig; config != nil {
+		if config.Affinity != nil {
+			daemon.Spec.Template.Spec.Affinity = config.Affinity
 		}
+		for j, container := range daemon.Spec.Template.Spec.Containers {
+			if container.Name == "controller" && config.Resources != nil {
+				daemon.Spec.Template.Spec.Containers[j].Resources = *config.Resources
+			}
+		}
+		if len(config.Annotations) > 0 && daemon.Spec.Template.Annotations == nil {
+			daemon.Spec.Template.Annotations = map[string]string{}
+		}
+		for k, v := range config.Annotations {
+			daemon.Spec.Template.Annotations[k] = v
+		}
+	}
+	if config := crdConfig.Spec.SpeakerConfig; config != nil {
+		overrideContainerResources(daemon.Spec.Template.Spec.Containers, config.ContainerResources)
 	}
 	objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(daemon)
 	if err != nil {
@@ -2,6 +2,7 @@ package helm
 
 import (
 	"testing"
+	"time"
 
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
 	. "github.com/onsi/gomega"
@@ -9,6 +10,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -39,6 +41,7 @@ func TestParseFRRK8SChartWithCustomValues(t *testing.T) {
 		"kubernetes.io/os": "linux",
 	}
 	tolerations := []corev1.Toleration{{Key: "foo", Operator: corev1.TolerationOpExists}}
+	updateStrategy := &appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
 
 	metallb := &metallbv1beta1.MetalLB{
 		ObjectMeta: metav1.ObjectMeta{
@@ -47,12 +50,17 @@ func TestParseFRRK8SChartWithCustomValues(t *testing.T) {
 		},
 		Spec: metallbv1beta1.MetalLBSpec{
 			LogLevel:            metallbv1beta1.LogLevelDebug,
+			BGPDebounceTimeout:  &metav1.Duration{Duration: 5 * time.Second},
 			SpeakerNodeSelector: nodeSelector,
 			SpeakerTolerations:  tolerations,
 			SpeakerConfig: &metallbv1beta1.Config{
 				PriorityClassName: "high-priority",
 				RuntimeClassName:  "cri-o",
-				Resources:         &corev1.ResourceRequirements{Limits: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: *resource.NewMilliQuantity(200, resource.DecimalSI)}},
+				UpdateStrategy:    updateStrategy,
+				ContainerResources: map[string]corev1.ResourceRequirements{
+					"frr": {Limits: map[corev1.ResourceName]resource.Quantity{corev1.ResourceMemory: resource.MustParse("300Mi")}},
+				},
+				Resources: &corev1.ResourceRequirements{Limits: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: *resource.NewMilliQuantity(200, resource.DecimalSI)}},
 				Affinity: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{LabelSelector: &metav1.LabelSelector{
 					MatchLabels: map[string]string{
 						"app": "metallb",
@@ -79,9 +87,14 @@ func TestParseFRRK8SChartWithCustomValues(t *testing.T) {
 			g.Expect(frrk8s.GetName()).To(Equal(frrk8sDaemonSetName))
 			g.Expect(frrk8s.Spec.Template.Spec.PriorityClassName).To(Equal("high-priority"))
 			g.Expect(*frrk8s.Spec.Template.Spec.RuntimeClassName).To(Equal("cri-o"))
+			g.Expect(frrk8s.Spec.UpdateStrategy).To(Equal(*updateStrategy))
 			g.Expect(frrk8s.Spec.Template.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"]).To(Equal("metallb"))
-			var frrk8sControllerFound bool
+			var frrk8sControllerFound, frrFound bool
 			for _, container := range frrk8s.Spec.Template.Spec.Containers {
+				if container.Name == "frr" {
+					g.Expect(container.Resources.Limits.Memory().String()).To(Equal("300Mi"))
+					frrFound = true
+				}
 				if container.Name == "controller" {
 					g.Expect(container.Image == "frr-k8s:test")
 					frrk8sControllerFound = true
@@ -97,11 +110,13 @@ func TestParseFRRK8SChartWithCustomValues(t *testing.T) {
 					}
 					g.Expect(logLevelChanged).To(BeTrue())
 					g.Expect(alwaysBlockChanged).To(BeTrue())
+					g.Expect(container.Args).To(ContainElement("--bgp-debounce-timeout=5000"))
 					g.Expect(container.Resources).NotTo(BeNil())
 					g.Expect(container.Resources.Limits.Cpu().MilliValue()).To(Equal(int64(200)))
 				}
 			}
 			g.Expect(frrk8sControllerFound).To(BeTrue())
+			g.Expect(frrFound).To(BeTrue())
 			g.Expect(frrk8s.Spec.Template.Spec.NodeSelector).To(Equal(nodeSelector))
 			g.Expect(frrk8s.Spec.Template.Spec.Tolerations).To(ContainElement(tolerations[0]))
 			isFRRK8SFound = true
@@ -126,6 +141,116 @@ func TestParseFRRK8SChartWithCustomValues(t *testing.T) {
 	g.Expect(isFRRK8SWebhookFound).To(BeTrue())
 }
 
+func TestFRRK8SDaemonConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewFRRK8SChart(frrk8sHelmChartPath, frrk8sHelmChartName, MetalLBTestNameSpace)
+	g.Expect(err).To(BeNil())
+
+	speakerAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+	frrk8sAffinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+	frrk8sNodeSelector := map[string]string{"frr": "true"}
+	frrk8sTolerations := []corev1.Toleration{{Key: "routing", Operator: corev1.TolerationOpExists}}
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend:          metallbv1beta1.FRRK8sMode,
+			SpeakerNodeSelector: map[string]string{"speaker": "true"},
+			SpeakerTolerations:  []corev1.Toleration{{Key: "speaker", Operator: corev1.TolerationOpExists}},
+			SpeakerConfig: &metallbv1beta1.Config{
+				PriorityClassName: "speaker-priority",
+				RuntimeClassName:  "cri-o",
+				Affinity:          speakerAffinity,
+				Resources:         &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}},
+			},
+			FRRK8SConfig: &metallbv1beta1.FRRK8SConfig{
+				NodeSelector:      frrk8sNodeSelector,
+				Tolerations:       frrk8sTolerations,
+				Affinity:          frrk8sAffinity,
+				PriorityClassName: "frrk8s-priority",
+				Resources:         &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}},
+				Annotations:       map[string]string{"unittest": "frrk8s"},
+			},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	var frrk8sFound bool
+	for _, obj := range objs {
+		if !isFRRK8SDaemonset(obj) {
+			continue
+		}
+		frrk8sFound = true
+		frrk8s := appsv1.DaemonSet{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &frrk8s)
+		g.Expect(err).To(BeNil())
+		podSpec := frrk8s.Spec.Template.Spec
+		g.Expect(podSpec.NodeSelector).To(HaveKeyWithValue("frr", "true"))
+		g.Expect(podSpec.NodeSelector).NotTo(HaveKey("speaker"))
+		g.Expect(podSpec.Tolerations).To(ContainElement(frrk8sTolerations[0]))
+		g.Expect(podSpec.Tolerations).NotTo(ContainElement(HaveField("Key", "speaker")))
+		g.Expect(podSpec.Affinity).To(Equal(frrk8sAffinity))
+		g.Expect(podSpec.PriorityClassName).To(Equal("frrk8s-priority"))
+		g.Expect(*podSpec.RuntimeClassName).To(Equal("cri-o"))
+		g.Expect(frrk8s.Spec.Template.Annotations).To(HaveKeyWithValue("unittest", "frrk8s"))
+		var controllerFound bool
+		for _, container := range podSpec.Containers {
+			if container.Name == "controller" {
+				controllerFound = true
+				g.Expect(container.Resources.Limits.Cpu().MilliValue()).To(Equal(int64(300)))
+			}
+		}
+		g.Expect(controllerFound).To(BeTrue())
+	}
+	g.Expect(frrk8sFound).To(BeTrue())
+}
+
+func TestFRRK8SContainerResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewFRRK8SChart(frrk8sHelmChartPath, frrk8sHelmChartName, MetalLBTestNameSpace)
+	g.Expect(err).To(BeNil())
+
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend: metallbv1beta1.FRRK8sMode,
+			SpeakerConfig: &metallbv1beta1.Config{
+				ContainerResources: map[string]corev1.ResourceRequirements{
+					"controller": {Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("400m")}},
+				},
+			},
+			FRRK8SConfig: &metallbv1beta1.FRRK8SConfig{
+				Resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}},
+			},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	var controllerFound bool
+	for _, obj := range objs {
+		if !isFRRK8SDaemonset(obj) {
+			continue
+		}
+		frrk8s := appsv1.DaemonSet{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &frrk8s)
+		g.Expect(err).To(BeNil())
+		for _, container := range frrk8s.Spec.Template.Spec.Containers {
+			if container.Name == "controller" {
+				controllerFound = true
+				g.Expect(container.Resources.Limits.Cpu().MilliValue()).To(Equal(int64(400)))
+			}
+		}
+	}
+	g.Expect(controllerFound).To(BeTrue())
+}
+
 func TestParseFRRK8SOCPSecureMetrics(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -171,3 +296,161 @@ func TestParseFRRK8SOCPSecureMetrics(t *testing.T) {
 		}
 	}
 }
+
+func TestFRRK8SImages(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewFRRK8SChart(frrk8sHelmChartPath, frrk8sHelmChartName, MetalLBTestNameSpace)
+	g.Expect(err).To(BeNil())
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend:       metallbv1beta1.FRRK8sMode,
+			ImageRegistry:    "mirror.example.com:5000",
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror-pull-secret"}},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	workloads := 0
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" && obj.GetKind() != "DaemonSet" {
+			continue
+		}
+		workloads++
+		podSpec, found, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+		g.Expect(err).To(BeNil())
+		g.Expect(found).To(BeTrue())
+		spec := corev1.PodSpec{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(podSpec, &spec)
+		g.Expect(err).To(BeNil())
+		g.Expect(spec.ImagePullSecrets).To(Equal(metallb.Spec.ImagePullSecrets), obj.GetName())
+		for _, c := range append(spec.InitContainers, spec.Containers...) {
+			g.Expect(c.Image).To(HavePrefix("mirror.example.com:5000/"), obj.GetName())
+		}
+	}
+	g.Expect(workloads).To(BeNumerically(">", 1))
+}
+
+func TestFRRK8SAlerts(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewFRRK8SChart(frrk8sHelmChartPath, frrk8sHelmChartName, MetalLBTestNameSpace)
+	g.Expect(err).To(BeNil())
+	enabled := true
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend: metallbv1beta1.FRRK8sMode,
+			Monitoring: &metallbv1beta1.MonitoringConfig{
+				Type: metallbv1beta1.ServiceMonitorType,
+				Alerts: &metallbv1beta1.AlertsConfig{
+					Enabled:          &enabled,
+					Severities:       map[string]string{"FRRK8sBFDSessionDown": "warning", "MetalLBStaleConfig": "info"},
+					AdditionalLabels: map[string]string{"prometheus": "k8s"},
+				},
+			},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	var rule *unstructured.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() == "PrometheusRule" {
+			rule = obj
+		}
+	}
+	g.Expect(rule).NotTo(BeNil())
+	g.Expect(rule.GetNamespace()).To(Equal(defaultEnvConfig.Namespace))
+	g.Expect(rule.GetLabels()).To(HaveKeyWithValue("prometheus", "k8s"))
+	groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	g.Expect(err).To(BeNil())
+	rules, _, err := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+	g.Expect(err).To(BeNil())
+	severities := map[string]interface{}{}
+	for _, r := range rules {
+		alert := r.(map[string]interface{})
+		severity, _, _ := unstructured.NestedString(alert, "labels", "severity")
+		severities[alert["alert"].(string)] = severity
+	}
+	g.Expect(severities).To(Equal(map[string]interface{}{
+		"FRRK8sBGPSessionDown": "critical",
+		"FRRK8sBFDSessionDown": "warning",
+	}))
+
+	// The alerts can't fire without the metrics renamed by the ServiceMonitor.
+	metallb.Spec.Monitoring.Type = metallbv1beta1.PodMonitorType
+	objs, err = chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	for _, obj := range objs {
+		g.Expect(obj.GetKind()).NotTo(Equal("PrometheusRule"))
+	}
+
+	metallb.Spec.Monitoring.Type = metallbv1beta1.ServiceMonitorType
+	metallb.Spec.Monitoring.Alerts.Enabled = nil
+	objs, err = chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	for _, obj := range objs {
+		g.Expect(obj.GetKind()).NotTo(Equal("PrometheusRule"))
+	}
+}
+
+func TestFRRK8SMonitoring(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewFRRK8SChart(frrk8sHelmChartPath, frrk8sHelmChartName, MetalLBTestNameSpace)
+	g.Expect(err).To(BeNil())
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend: metallbv1beta1.FRRK8sMode,
+			Monitoring: &metallbv1beta1.MonitoringConfig{
+				Type:             metallbv1beta1.ServiceMonitorType,
+				Interval:         &metav1.Duration{Duration: 30 * time.Second},
+				AdditionalLabels: map[string]string{"prometheus": "k8s"},
+				MetricRelabelings: []metallbv1beta1.RelabelConfig{
+					{SourceLabels: []string{"__name__"}, Regex: "metallb_bfd_(.*)", Action: "drop"},
+				},
+			},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	var monitor *unstructured.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() == "ServiceMonitor" {
+			monitor = obj
+		}
+	}
+	g.Expect(monitor).NotTo(BeNil())
+	g.Expect(monitor.GetLabels()).To(HaveKeyWithValue("prometheus", "k8s"))
+	endpoints, _, err := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	g.Expect(err).To(BeNil())
+	g.Expect(endpoints).NotTo(BeEmpty())
+	for _, e := range endpoints {
+		endpoint := e.(map[string]interface{})
+		g.Expect(endpoint).To(HaveKeyWithValue("interval", "30s"))
+		relabelings, _, err := unstructured.NestedSlice(endpoint, "metricRelabelings")
+		g.Expect(err).To(BeNil())
+		// The user relabelings apply after the ones renaming the frr-k8s metrics.
+		g.Expect(relabelings).To(HaveLen(3))
+		g.Expect(relabelings[0]).To(HaveKeyWithValue("replacement", "metallb_bgp_$1"))
+		g.Expect(relabelings[2]).To(HaveKeyWithValue("action", "drop"))
+	}
+
+	metallb.Spec.Monitoring.Type = metallbv1beta1.PodMonitorType
+	objs, err = chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	for _, obj := range objs {
+		g.Expect(obj.GetKind()).NotTo(Equal("ServiceMonitor"))
+	}
+}
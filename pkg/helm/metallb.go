@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 // MetalLBChart metallb chart struct containing references which helps to
@@ -76,6 +77,19 @@ func (h *MetalLBChart) Objects(envConfig params.EnvConfig, crdConfig *metallbv1b
 		if err != nil {
 			return nil, err
 		}
+		objs[i], err = overrideExcludedInterfaces(crdConfig, objs[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := overrideMonitorEndpoints(crdConfig, objs[i]); err != nil {
+			return nil, err
+		}
+		if err := overrideImageDigests(envConfig, objs[i]); err != nil {
+			return nil, err
+		}
+		if err := overrideImageRegistry(crdConfig, objs[i]); err != nil {
+			return nil, err
+		}
 		// we need to override the security context as helm values are added on top
 		// of hardcoded ones in values.yaml, so it's not possible to reset runAsUser
 		if isControllerDeployment(obj) && envConfig.IsOpenshift {
@@ -140,11 +154,15 @@ func overrideControllerParameters(crdConfig *metallbv1beta1.MetalLB, obj *unstru
 	if controllerConfig.Affinity != nil {
 		controller.Spec.Template.Spec.Affinity = controllerConfig.Affinity
 	}
+	if controllerConfig.Replicas != nil {
+		controller.Spec.Replicas = controllerConfig.Replicas
+	}
 	for j, container := range controller.Spec.Template.Spec.Containers {
 		if container.Name == "controller" && controllerConfig.Resources != nil {
 			controller.Spec.Template.Spec.Containers[j].Resources = *controllerConfig.Resources
 		}
 	}
+	overrideContainerResources(controller.Spec.Template.Spec.Containers, controllerConfig.ContainerResources)
 	objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(controller)
 	if err != nil {
 		return nil, err
@@ -165,11 +183,15 @@ func overrideSpeakerParameters(crdConfig *metallbv1beta1.MetalLB, obj *unstructu
 	if speakerConfig.Affinity != nil {
 		speaker.Spec.Template.Spec.Affinity = speakerConfig.Affinity
 	}
+	if speakerConfig.UpdateStrategy != nil {
+		speaker.Spec.UpdateStrategy = *speakerConfig.UpdateStrategy
+	}
 	for j, container := range speaker.Spec.Template.Spec.Containers {
 		if container.Name == "speaker" && speakerConfig.Resources != nil {
 			speaker.Spec.Template.Spec.Containers[j].Resources = *speakerConfig.Resources
 		}
 	}
+	overrideContainerResources(speaker.Spec.Template.Spec.Containers, speakerConfig.ContainerResources)
 	objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(speaker)
 	if err != nil {
 		return nil, err
@@ -177,6 +199,35 @@ func overrideSpeakerParameters(crdConfig *metallbv1beta1.MetalLB, obj *unstructu
 	return &unstructured.Unstructured{Object: objMap}, nil
 }
 
+// overrideExcludedInterfaces appends the user provided interfaces to the ones
+// the chart excludes from the L2 announcements, as the chart doesn't allow
+// to extend its hardcoded list.
+func overrideExcludedInterfaces(crdConfig *metallbv1beta1.MetalLB, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	excludeConfig := crdConfig.Spec.ExcludeInterfaces
+	if excludeConfig == nil || len(excludeConfig.Interfaces) == 0 || !isExcludeL2ConfigMap(obj) {
+		return obj, nil
+	}
+	data, _, err := unstructured.NestedString(obj.Object, "data", excludeL2ConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	excludeL2 := struct {
+		AnnouncedInterfacesToExclude []string `json:"announcedInterfacesToExclude"`
+	}{}
+	if err := yaml.Unmarshal([]byte(data), &excludeL2); err != nil {
+		return nil, err
+	}
+	excludeL2.AnnouncedInterfacesToExclude = append(excludeL2.AnnouncedInterfacesToExclude, excludeConfig.Interfaces...)
+	res, err := yaml.Marshal(excludeL2)
+	if err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(obj.Object, string(res), "data", excludeL2ConfigKey); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
 func isControllerDeployment(obj *unstructured.Unstructured) bool {
 	return obj.GetKind() == "Deployment" && obj.GetName() == "controller"
 }
@@ -189,19 +240,29 @@ func isServiceMonitor(obj *unstructured.Unstructured) bool {
 	return obj.GetKind() == "ServiceMonitor"
 }
 
+func isExcludeL2ConfigMap(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "ConfigMap" && obj.GetName() == excludeL2ConfigMapName
+}
+
 const (
 	controllerCertsSecret = "controller-certs-secret"
 	speakerCertsSecret    = "speaker-certs-secret"
+
+	excludeL2ConfigMapName = "metallb-excludel2"
+	excludeL2ConfigKey     = "excludel2.yaml"
 )
 
 func patchMetalLBChartValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB, valuesMap map[string]interface{}) {
 	valuesMap["loadBalancerClass"] = loadBalancerClassValue(crdConfig)
-	valuesMap["prometheus"] = metalLBprometheusValues(envConfig)
+	valuesMap["prometheus"] = metalLBprometheusValues(envConfig, crdConfig)
 	valuesMap["controller"] = controllerValues(envConfig, crdConfig)
 	valuesMap["speaker"] = speakerValues(envConfig, crdConfig)
 	valuesMap["frrk8s"] = metalLBFrrk8sValues(envConfig, crdConfig)
 	valuesMap["networkpolicies"] = netpolValues(envConfig)
 	valuesMap["tls"] = metallbTLSHelmValues(envConfig)
+	if len(crdConfig.Spec.ImagePullSecrets) > 0 {
+		valuesMap["imagePullSecrets"] = crdConfig.Spec.ImagePullSecrets
+	}
 }
 
 func metallbTLSHelmValues(envConfig params.EnvConfig) map[string]interface{} {
@@ -221,7 +282,7 @@ func loadBalancerClassValue(crdConfig *metallbv1beta1.MetalLB) string {
 	return crdConfig.Spec.LoadBalancerClass
 }
 
-func metalLBprometheusValues(envConfig params.EnvConfig) map[string]interface{} {
+func metalLBprometheusValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
 	speakerTLSConfig := map[string]interface{}{
 		"insecureSkipVerify": true,
 	}
@@ -238,27 +299,63 @@ func metalLBprometheusValues(envConfig params.EnvConfig) map[string]interface{}
 		controllerAnnotations = ocpServingCertAnnotationFor(controllerCertsSecret)
 	}
 
+	additionalLabels := monitorLabelsValue(crdConfig)
 	return map[string]interface{}{
 		"metricsPort": envConfig.MetricsPort,
 		"podMonitor": map[string]interface{}{
-			"enabled": envConfig.DeployPodMonitors,
+			"enabled":          params.PodMonitorsEnabled(crdConfig, envConfig),
+			"additionalLabels": additionalLabels,
 		},
 		"serviceMonitor": map[string]interface{}{
-			"enabled": envConfig.DeployServiceMonitors,
+			"enabled": params.ServiceMonitorsEnabled(crdConfig, envConfig),
 			"speaker": map[string]interface{}{
-				"annotations": speakerAnnotations,
-				"tlsConfig":   speakerTLSConfig,
+				"additionalLabels": additionalLabels,
+				"annotations":      speakerAnnotations,
+				"tlsConfig":        speakerTLSConfig,
 			},
 			"controller": map[string]interface{}{
-				"annotations": controllerAnnotations,
-				"tlsConfig":   controllerTLSConfig,
+				"additionalLabels": additionalLabels,
+				"annotations":      controllerAnnotations,
+				"tlsConfig":        controllerTLSConfig,
 			},
 		},
+		"prometheusRule": prometheusRuleValues(envConfig, crdConfig),
 		"serviceAccount": "foo", // required by the chart, we won't render roles or rolebindings anyway
 		"namespace":      "bar",
 	}
 }
 
+// metallbAlertValues maps the alerts of the MetalLB chart to their values.
+var metallbAlertValues = map[string]string{
+	"MetalLBStaleConfig":          "staleConfig",
+	"MetalLBConfigNotLoaded":      "configNotLoaded",
+	"MetalLBAddressPoolExhausted": "addressPoolExhausted",
+	"MetalLBBGPSessionDown":       "bgpSessionDown",
+}
+
+func prometheusRuleValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
+	res := map[string]interface{}{
+		"enabled": params.AlertsEnabled(crdConfig, envConfig),
+	}
+	alerts := alertsConfig(crdConfig)
+	if alerts == nil {
+		return res
+	}
+	if alerts.AdditionalLabels != nil {
+		res["additionalLabels"] = toInterfaceMap(alerts.AdditionalLabels)
+	}
+	for alert, severity := range alerts.Severities {
+		value, ok := metallbAlertValues[alert]
+		if !ok {
+			continue
+		}
+		res[value] = map[string]interface{}{
+			"labels": map[string]interface{}{"severity": severity},
+		}
+	}
+	return res
+}
+
 func controllerValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
 	controllerValueMap := map[string]interface{}{
 		"image": map[string]interface{}{
@@ -330,9 +427,10 @@ func speakerValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB
 			"metricsPort": envConfig.FRRMetricsPort,
 		},
 		"memberlist": map[string]interface{}{
-			"enabled":    true,
-			"mlBindPort": envConfig.MLBindPort,
+			"enabled":    params.MemberlistEnabled(crdConfig),
+			"mlBindPort": params.MemberlistBindPort(crdConfig, envConfig),
 		},
+		"secretName": params.MemberlistSecretName(crdConfig),
 		"livenessProbe": map[string]interface{}{
 			"enabled": true,
 			"port":    envConfig.LivenessPort,
@@ -344,6 +442,11 @@ func speakerValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB
 		"command": "/speaker",
 	}
 	speakerValueMap["logLevel"] = logLevelValue(crdConfig)
+	speakerValueMap["excludeInterfaces"] = map[string]interface{}{
+		"enabled": excludeInterfacesEnabled(crdConfig),
+	}
+	speakerValueMap["ignoreExcludeLB"] = crdConfig.Spec.IgnoreExcludeLB
+	speakerValueMap["bgpDebounceTimeout"] = bgpDebounceTimeoutValue(crdConfig)
 	if crdConfig.Spec.SpeakerNodeSelector != nil {
 		speakerValueMap["nodeSelector"] = toInterfaceMap(crdConfig.Spec.SpeakerNodeSelector)
 	}
@@ -375,10 +478,7 @@ func netpolValues(envConfig params.EnvConfig) map[string]any {
 func metalLBFrrk8sValues(envConfig params.EnvConfig, crdConfig *metallbv1beta1.MetalLB) map[string]interface{} {
 	enabled := params.BGPType(crdConfig, envConfig) == metallbv1beta1.FRRK8sMode
 
-	frrK8sNamespace := envConfig.FRRK8sExternalNamespace
-	if crdConfig.Spec.FRRK8SConfig != nil && crdConfig.Spec.FRRK8SConfig.Namespace != "" {
-		frrK8sNamespace = crdConfig.Spec.FRRK8SConfig.Namespace
-	}
+	frrK8sNamespace := params.FRRK8sNamespace(crdConfig, envConfig)
 
 	external := params.BGPType(crdConfig, envConfig) == metallbv1beta1.FRRK8sExternalMode
 	secretPassthrough := false
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
@@ -34,6 +35,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 )
 
 var update = flag.Bool("update", false, "update .golden files")
@@ -115,10 +118,16 @@ func TestParseMetalLBChartWithCustomValues(t *testing.T) {
 				"app": "metallb",
 			}}}}}},
 	}
+	maxUnavailable := intstr.FromString("10%")
+	speakerUpdateStrategy := &appsv1.DaemonSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+	}
 	speakerConfig := &metallbv1beta1.Config{
 		PriorityClassName: "high-priority",
 		RuntimeClassName:  "cri-o",
 		Annotations:       map[string]string{"unittest": "speaker"},
+		UpdateStrategy:    speakerUpdateStrategy,
 		Resources:         &v1.ResourceRequirements{Limits: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: *resource.NewMilliQuantity(200, resource.DecimalSI)}},
 		Affinity: &v1.Affinity{PodAffinity: &v1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{LabelSelector: &metav1.LabelSelector{
 			MatchLabels: map[string]string{
@@ -178,6 +187,7 @@ func TestParseMetalLBChartWithCustomValues(t *testing.T) {
 			g.Expect(speaker.Spec.Template.Spec.PriorityClassName).To(Equal("high-priority"))
 			g.Expect(*speaker.Spec.Template.Spec.RuntimeClassName).To(Equal("cri-o"))
 			g.Expect(speaker.Spec.Template.Annotations["unittest"]).To(Equal("speaker"))
+			g.Expect(speaker.Spec.UpdateStrategy).To(Equal(*speakerUpdateStrategy))
 			g.Expect(speaker.Spec.Template.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"]).To(Equal("metallb"))
 			var speakerContainerFound bool
 			for _, container := range speaker.Spec.Template.Spec.Containers {
@@ -280,6 +290,346 @@ func TestSecretPassthrough(t *testing.T) {
 	}
 }
 
+func TestExcludeInterfaces(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name               string
+		excludeInterfaces  *metallbv1beta1.ExcludeInterfaces
+		ignoreExcludeLB    bool
+		expectConfigMap    bool
+		expectedInterfaces []string
+	}{
+		{
+			name:               "default",
+			expectConfigMap:    true,
+			expectedInterfaces: []string{"^docker.*", "^lo$"},
+		},
+		{
+			name: "additional interfaces",
+			excludeInterfaces: &metallbv1beta1.ExcludeInterfaces{
+				Interfaces: []string{"^storage.*", "^eth2$"},
+			},
+			ignoreExcludeLB:    true,
+			expectConfigMap:    true,
+			expectedInterfaces: []string{"^docker.*", "^lo$", "^storage.*", "^eth2$"},
+		},
+		{
+			name: "disabled",
+			excludeInterfaces: &metallbv1beta1.ExcludeInterfaces{
+				Enabled: &disabled,
+			},
+			expectConfigMap: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					ExcludeInterfaces: tt.excludeInterfaces,
+					IgnoreExcludeLB:   tt.ignoreExcludeLB,
+				},
+			}
+
+			objs, err := chart.Objects(defaultEnvConfig, metallb)
+			g.Expect(err).To(BeNil())
+			var configMapFound, speakerFound bool
+			for _, obj := range objs {
+				if isExcludeL2ConfigMap(obj) {
+					configMapFound = true
+					data, _, err := unstructured.NestedString(obj.Object, "data", excludeL2ConfigKey)
+					g.Expect(err).To(BeNil())
+					for _, i := range tt.expectedInterfaces {
+						g.Expect(data).To(ContainSubstring(i))
+					}
+				}
+				if isSpeakerDaemonSet(obj) {
+					speakerFound = true
+					speaker := appsv1.DaemonSet{}
+					err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &speaker)
+					g.Expect(err).To(BeNil())
+					volumeMatcher := ContainElement(HaveField("Name", excludeL2ConfigMapName))
+					if tt.expectConfigMap {
+						g.Expect(speaker.Spec.Template.Spec.Volumes).To(volumeMatcher)
+					} else {
+						g.Expect(speaker.Spec.Template.Spec.Volumes).NotTo(volumeMatcher)
+					}
+					for _, container := range speaker.Spec.Template.Spec.Containers {
+						if container.Name != "speaker" {
+							continue
+						}
+						if tt.ignoreExcludeLB {
+							g.Expect(container.Args).To(ContainElement("--ignore-exclude-lb"))
+						} else {
+							g.Expect(container.Args).NotTo(ContainElement("--ignore-exclude-lb"))
+						}
+					}
+				}
+			}
+			g.Expect(configMapFound).To(Equal(tt.expectConfigMap))
+			g.Expect(speakerFound).To(BeTrue())
+		})
+	}
+}
+
+func TestBGPDebounceTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     *metav1.Duration
+		expectedArg string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:        "custom",
+			timeout:     &metav1.Duration{Duration: 1500 * time.Millisecond},
+			expectedArg: "--bgp-debounce-timeout=1500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					BGPBackend:         metallbv1beta1.FRRMode,
+					BGPDebounceTimeout: tt.timeout,
+				},
+			}
+
+			objs, err := chart.Objects(defaultEnvConfig, metallb)
+			g.Expect(err).To(BeNil())
+			var speakerContainerFound bool
+			for _, obj := range objs {
+				if !isSpeakerDaemonSet(obj) {
+					continue
+				}
+				speaker := appsv1.DaemonSet{}
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &speaker)
+				g.Expect(err).To(BeNil())
+				for _, container := range speaker.Spec.Template.Spec.Containers {
+					if container.Name != "speaker" {
+						continue
+					}
+					speakerContainerFound = true
+					if tt.expectedArg == "" {
+						g.Expect(container.Args).NotTo(ContainElement(HavePrefix("--bgp-debounce-timeout")))
+						continue
+					}
+					g.Expect(container.Args).To(ContainElement(tt.expectedArg))
+				}
+			}
+			g.Expect(speakerContainerFound).To(BeTrue())
+		})
+	}
+}
+
+func TestMemberlist(t *testing.T) {
+	disabled := false
+	bindPort := int32(9999)
+	tests := []struct {
+		name               string
+		memberlist         *metallbv1beta1.MemberlistConfig
+		expectEnabled      bool
+		expectedPort       int32
+		expectedSecretName string
+	}{
+		{
+			name:               "default",
+			expectEnabled:      true,
+			expectedPort:       7946,
+			expectedSecretName: "metallb-memberlist",
+		},
+		{
+			name: "custom port and secret",
+			memberlist: &metallbv1beta1.MemberlistConfig{
+				BindPort:   &bindPort,
+				SecretName: "my-memberlist",
+			},
+			expectEnabled:      true,
+			expectedPort:       9999,
+			expectedSecretName: "my-memberlist",
+		},
+		{
+			name: "disabled",
+			memberlist: &metallbv1beta1.MemberlistConfig{
+				Enabled: &disabled,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					Memberlist: tt.memberlist,
+				},
+			}
+
+			objs, err := chart.Objects(defaultEnvConfig, metallb)
+			g.Expect(err).To(BeNil())
+			var speakerFound bool
+			for _, obj := range objs {
+				if !isSpeakerDaemonSet(obj) {
+					continue
+				}
+				speakerFound = true
+				speaker := appsv1.DaemonSet{}
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &speaker)
+				g.Expect(err).To(BeNil())
+				volumeMatcher := ContainElement(And(
+					HaveField("Name", "memberlist"),
+					HaveField("Secret.SecretName", tt.expectedSecretName),
+				))
+				if !tt.expectEnabled {
+					volumeMatcher = Not(ContainElement(HaveField("Name", "memberlist")))
+				}
+				g.Expect(speaker.Spec.Template.Spec.Volumes).To(volumeMatcher)
+				for _, container := range speaker.Spec.Template.Spec.Containers {
+					if container.Name != "speaker" {
+						continue
+					}
+					portMatcher := ContainElement(And(
+						HaveField("Name", "memberlist-tcp"),
+						HaveField("ContainerPort", tt.expectedPort),
+					))
+					if !tt.expectEnabled {
+						portMatcher = Not(ContainElement(HaveField("Name", "memberlist-tcp")))
+					}
+					g.Expect(container.Ports).To(portMatcher)
+				}
+			}
+			g.Expect(speakerFound).To(BeTrue())
+		})
+	}
+}
+
+func TestControllerReplicas(t *testing.T) {
+	tests := []struct {
+		name             string
+		replicas         *int32
+		expectedReplicas *int32
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:             "single replica",
+			replicas:         ptr.To(int32(1)),
+			expectedReplicas: ptr.To(int32(1)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					ControllerConfig: &metallbv1beta1.Config{
+						Replicas: tt.replicas,
+					},
+				},
+			}
+
+			objs, err := chart.Objects(defaultEnvConfig, metallb)
+			g.Expect(err).To(BeNil())
+			var controller *appsv1.Deployment
+			for _, obj := range objs {
+				if isControllerDeployment(obj) {
+					controller = &appsv1.Deployment{}
+					err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), controller)
+					g.Expect(err).To(BeNil())
+				}
+			}
+			g.Expect(controller).NotTo(BeNil())
+			g.Expect(controller.Spec.Replicas).To(Equal(tt.expectedReplicas))
+		})
+	}
+}
+
+func TestContainerResources(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+	g.Expect(err).To(BeNil())
+
+	speakerResources := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")}}
+	frrResources := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")}}
+	reloaderResources := v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m")}}
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend: metallbv1beta1.FRRMode,
+			SpeakerConfig: &metallbv1beta1.Config{
+				Resources: &speakerResources,
+				ContainerResources: map[string]v1.ResourceRequirements{
+					"frr":      frrResources,
+					"reloader": reloaderResources,
+				},
+			},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	expected := map[string]v1.ResourceRequirements{
+		"speaker":  speakerResources,
+		"frr":      frrResources,
+		"reloader": reloaderResources,
+	}
+	var speakerFound bool
+	for _, obj := range objs {
+		if !isSpeakerDaemonSet(obj) {
+			continue
+		}
+		speakerFound = true
+		speaker := appsv1.DaemonSet{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &speaker)
+		g.Expect(err).To(BeNil())
+		found := map[string]v1.ResourceRequirements{}
+		for _, container := range speaker.Spec.Template.Spec.Containers {
+			if _, ok := expected[container.Name]; ok {
+				found[container.Name] = container.Resources
+			}
+		}
+		g.Expect(found).To(Equal(expected))
+	}
+	g.Expect(speakerFound).To(BeTrue())
+}
+
 func TestParseOCPSecureMetrics(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -491,3 +841,287 @@ func validateObject(testcase, name string, obj *unstructured.Unstructured) error
 	}
 	return nil
 }
+
+func TestImages(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+	g.Expect(err).To(BeNil())
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend:       metallbv1beta1.FRRMode,
+			ImageRegistry:    "mirror.example.com:5000",
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "mirror-pull-secret"}},
+		},
+	}
+
+	objs, err := chart.Objects(defaultEnvConfig, metallb)
+	g.Expect(err).To(BeNil())
+	images := []string{}
+	for _, obj := range objs {
+		if !isControllerDeployment(obj) && !isSpeakerDaemonSet(obj) {
+			continue
+		}
+		podSpec, found, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+		g.Expect(err).To(BeNil())
+		g.Expect(found).To(BeTrue())
+		spec := v1.PodSpec{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(podSpec, &spec)
+		g.Expect(err).To(BeNil())
+		g.Expect(spec.ImagePullSecrets).To(Equal(metallb.Spec.ImagePullSecrets))
+		for _, c := range append(spec.InitContainers, spec.Containers...) {
+			images = append(images, c.Image)
+		}
+	}
+	g.Expect(images).To(ContainElements(
+		"mirror.example.com:5000/metallb/controller:v0.0.0",
+		"mirror.example.com:5000/metallb/speaker:v0.0.0",
+		"mirror.example.com:5000/frrouting/frr:v7.5.1",
+	))
+	g.Expect(images).To(HaveEach(HavePrefix("mirror.example.com:5000/")))
+}
+
+func TestImageDigests(t *testing.T) {
+	g := NewGomegaWithT(t)
+	chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+	g.Expect(err).To(BeNil())
+	envConfig := defaultEnvConfig
+	envConfig.SpeakerImage = params.ImageInfo{Repo: "quay.io/metallb/speaker", Digest: "sha256:0123"}
+	envConfig.FRRImage = params.ImageInfo{Repo: "frrouting/frr", Tag: "v7.5.1", Digest: "sha256:4567"}
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metallb",
+			Namespace: MetalLBTestNameSpace,
+		},
+		Spec: metallbv1beta1.MetalLBSpec{
+			BGPBackend:    metallbv1beta1.FRRMode,
+			ImageRegistry: "mirror.example.com",
+		},
+	}
+
+	objs, err := chart.Objects(envConfig, metallb)
+	g.Expect(err).To(BeNil())
+	images := map[string]string{}
+	for _, obj := range objs {
+		if !isControllerDeployment(obj) && !isSpeakerDaemonSet(obj) {
+			continue
+		}
+		podSpec, found, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+		g.Expect(err).To(BeNil())
+		g.Expect(found).To(BeTrue())
+		spec := v1.PodSpec{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(podSpec, &spec)
+		g.Expect(err).To(BeNil())
+		for _, c := range append(spec.InitContainers, spec.Containers...) {
+			images[c.Name] = c.Image
+		}
+	}
+	g.Expect(images).To(HaveKeyWithValue("controller", "mirror.example.com/metallb/controller:v0.0.0"))
+	g.Expect(images).To(HaveKeyWithValue("speaker", "mirror.example.com/metallb/speaker@sha256:0123"))
+	g.Expect(images).To(HaveKeyWithValue("frr", "mirror.example.com/frrouting/frr:v7.5.1@sha256:4567"))
+}
+
+func TestAlerts(t *testing.T) {
+	tests := []struct {
+		desc       string
+		envEnabled bool
+		alerts     *metallbv1beta1.AlertsConfig
+		expected   bool
+	}{
+		{
+			desc: "disabled by default",
+		},
+		{
+			desc:       "enabled from the environment",
+			envEnabled: true,
+			expected:   true,
+		},
+		{
+			desc:       "disabled in the spec",
+			envEnabled: true,
+			alerts:     &metallbv1beta1.AlertsConfig{Enabled: ptr.To(false)},
+		},
+		{
+			desc: "enabled in the spec",
+			alerts: &metallbv1beta1.AlertsConfig{
+				Enabled:          ptr.To(true),
+				Severities:       map[string]string{"MetalLBStaleConfig": "critical", "FRRK8sBGPSessionDown": "info"},
+				AdditionalLabels: map[string]string{"prometheus": "k8s"},
+			},
+			expected: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+			envConfig := defaultEnvConfig
+			envConfig.DeployPrometheusRules = test.envEnabled
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					Monitoring: &metallbv1beta1.MonitoringConfig{Alerts: test.alerts},
+				},
+			}
+
+			objs, err := chart.Objects(envConfig, metallb)
+			g.Expect(err).To(BeNil())
+			var rule *unstructured.Unstructured
+			for _, obj := range objs {
+				if obj.GetKind() == "PrometheusRule" {
+					rule = obj
+				}
+			}
+			if !test.expected {
+				g.Expect(rule).To(BeNil())
+				return
+			}
+			g.Expect(rule).NotTo(BeNil())
+			groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+			g.Expect(err).To(BeNil())
+			g.Expect(groups).To(HaveLen(1))
+			rules, _, err := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+			g.Expect(err).To(BeNil())
+			severities := map[string]interface{}{}
+			for _, r := range rules {
+				alert := r.(map[string]interface{})
+				severity, _, _ := unstructured.NestedString(alert, "labels", "severity")
+				severities[alert["alert"].(string)] = severity
+			}
+			expectedStaleSeverity := "warning"
+			if test.alerts != nil {
+				g.Expect(rule.GetLabels()).To(HaveKeyWithValue("prometheus", "k8s"))
+				expectedStaleSeverity = "critical"
+			}
+			g.Expect(severities).To(HaveKeyWithValue("MetalLBStaleConfig", expectedStaleSeverity))
+			g.Expect(severities).To(HaveKeyWithValue("MetalLBConfigNotLoaded", "warning"))
+			g.Expect(severities).To(HaveKeyWithValue("MetalLBBGPSessionDown", "critical"))
+		})
+	}
+}
+
+func TestMonitoring(t *testing.T) {
+	relabeling := metallbv1beta1.RelabelConfig{
+		SourceLabels: []string{"__name__"},
+		Regex:        "metallb_k8s_client_(.*)",
+		Action:       "drop",
+	}
+	tests := []struct {
+		desc         string
+		envConfig    func(params.EnvConfig) params.EnvConfig
+		monitoring   *metallbv1beta1.MonitoringConfig
+		expectedKind string
+	}{
+		{
+			desc: "service monitors from the environment",
+			envConfig: func(env params.EnvConfig) params.EnvConfig {
+				env.DeployServiceMonitors = true
+				return env
+			},
+			expectedKind: "ServiceMonitor",
+		},
+		{
+			desc: "pod monitors from the environment",
+			envConfig: func(env params.EnvConfig) params.EnvConfig {
+				env.DeployPodMonitors = true
+				return env
+			},
+			expectedKind: "PodMonitor",
+		},
+		{
+			desc: "pod monitors from the spec",
+			envConfig: func(env params.EnvConfig) params.EnvConfig {
+				env.DeployServiceMonitors = true
+				return env
+			},
+			monitoring: &metallbv1beta1.MonitoringConfig{
+				Type:              metallbv1beta1.PodMonitorType,
+				Interval:          &metav1.Duration{Duration: time.Minute},
+				AdditionalLabels:  map[string]string{"prometheus": "k8s"},
+				MetricRelabelings: []metallbv1beta1.RelabelConfig{relabeling},
+			},
+			expectedKind: "PodMonitor",
+		},
+		{
+			desc: "service monitors from the spec",
+			envConfig: func(env params.EnvConfig) params.EnvConfig {
+				return env
+			},
+			monitoring: &metallbv1beta1.MonitoringConfig{
+				Type:              metallbv1beta1.ServiceMonitorType,
+				Interval:          &metav1.Duration{Duration: time.Minute},
+				AdditionalLabels:  map[string]string{"prometheus": "k8s"},
+				MetricRelabelings: []metallbv1beta1.RelabelConfig{relabeling},
+			},
+			expectedKind: "ServiceMonitor",
+		},
+		{
+			desc: "no monitors from the spec",
+			envConfig: func(env params.EnvConfig) params.EnvConfig {
+				env.DeployPodMonitors = true
+				return env
+			},
+			monitoring: &metallbv1beta1.MonitoringConfig{Type: metallbv1beta1.NoMonitorType},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+				Spec: metallbv1beta1.MetalLBSpec{
+					Monitoring: test.monitoring,
+				},
+			}
+
+			objs, err := chart.Objects(test.envConfig(defaultEnvConfig), metallb)
+			g.Expect(err).To(BeNil())
+			monitors := []*unstructured.Unstructured{}
+			for _, obj := range objs {
+				if obj.GetKind() == "ServiceMonitor" || obj.GetKind() == "PodMonitor" {
+					monitors = append(monitors, obj)
+				}
+			}
+			if test.expectedKind == "" {
+				g.Expect(monitors).To(BeEmpty())
+				return
+			}
+			g.Expect(monitors).To(HaveLen(2))
+			for _, m := range monitors {
+				g.Expect(m.GetKind()).To(Equal(test.expectedKind))
+				if test.monitoring == nil {
+					continue
+				}
+				g.Expect(m.GetLabels()).To(HaveKeyWithValue("prometheus", "k8s"))
+				endpointsField := "endpoints"
+				if m.GetKind() == "PodMonitor" {
+					endpointsField = "podMetricsEndpoints"
+				}
+				endpoints, _, err := unstructured.NestedSlice(m.Object, "spec", endpointsField)
+				g.Expect(err).To(BeNil())
+				g.Expect(endpoints).NotTo(BeEmpty())
+				for _, e := range endpoints {
+					endpoint := e.(map[string]interface{})
+					g.Expect(endpoint).To(HaveKeyWithValue("interval", "60s"))
+					g.Expect(endpoint).To(HaveKeyWithValue("metricRelabelings", ContainElement(map[string]interface{}{
+						"sourceLabels": []interface{}{"__name__"},
+						"regex":        "metallb_k8s_client_(.*)",
+						"action":       "drop",
+					})))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// workloadKinds are the kinds whose selector is immutable.
+var workloadKinds = []string{"Deployment", "DaemonSet"}
+
+// ApplyOverrides patches the rendered objects with the given overrides, in
+// order. Each override must match at least one of the objects and can't
+// change the identity of the objects it patches, nor the selector of the
+// workloads.
+func ApplyOverrides(objs []*unstructured.Unstructured, overrides []metallbv1beta1.ObjectOverride) error {
+	for i, override := range overrides {
+		found := false
+		for _, obj := range objs {
+			if obj.GetKind() != override.Kind || obj.GetName() != override.Name {
+				continue
+			}
+			found = true
+			patched, err := patchObject(obj, override)
+			if err != nil {
+				return errors.Wrapf(err, "failed to apply override %d to %s %s", i, override.Kind, override.Name)
+			}
+			if err := validatePatchedObject(obj, patched); err != nil {
+				return errors.Wrapf(err, "invalid override %d for %s %s", i, override.Kind, override.Name)
+			}
+			obj.Object = patched.Object
+		}
+		if !found {
+			return fmt.Errorf("override %d: %s %s not found among the rendered objects", i, override.Kind, override.Name)
+		}
+	}
+	return nil
+}
+
+// patchObject returns a copy of the object with the override applied. The
+// strategic merge patches of the kinds not known to the client-go scheme, for
+// example the ServiceMonitors, are applied as JSON merge patches.
+func patchObject(obj *unstructured.Unstructured, override metallbv1beta1.ObjectOverride) (*unstructured.Unstructured, error) {
+	original, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := yaml.YAMLToJSON([]byte(override.Patch))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the patch")
+	}
+
+	var res []byte
+	switch override.Type {
+	case "", metallbv1beta1.StrategicMergePatch:
+		dataStruct, err := scheme.Scheme.New(obj.GroupVersionKind())
+		if err != nil {
+			res, err = jsonpatch.MergePatch(original, patch)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		res, err = strategicpatch.StrategicMergePatch(original, patch, dataStruct)
+		if err != nil {
+			return nil, err
+		}
+	case metallbv1beta1.JSONPatch:
+		decoded, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the JSON patch")
+		}
+		res, err = decoded.Apply(original)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported patch type %s", override.Type)
+	}
+
+	patched := &unstructured.Unstructured{}
+	if err := patched.UnmarshalJSON(res); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+func validatePatchedObject(original, patched *unstructured.Unstructured) error {
+	if original.GroupVersionKind() != patched.GroupVersionKind() ||
+		original.GetName() != patched.GetName() ||
+		original.GetNamespace() != patched.GetNamespace() {
+		return errors.New("the apiVersion, kind, name and namespace of the object can't be changed")
+	}
+	for _, kind := range workloadKinds {
+		if original.GetKind() != kind {
+			continue
+		}
+		originalSelector, _, _ := unstructured.NestedFieldNoCopy(original.Object, "spec", "selector")
+		patchedSelector, _, _ := unstructured.NestedFieldNoCopy(patched.Object, "spec", "selector")
+		if !equality.Semantic.DeepEqual(originalSelector, patchedSelector) {
+			return errors.New("the selector is immutable and can't be changed")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"testing"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	tests := []struct {
+		desc      string
+		overrides []metallbv1beta1.ObjectOverride
+		validate  func(g *WithT, speaker *appsv1.DaemonSet, controller *appsv1.Deployment)
+		shouldErr bool
+	}{
+		{
+			desc: "strategic merge patch merges the containers by name",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{
+					Kind: "DaemonSet",
+					Name: "speaker",
+					Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: speaker
+        env:
+        - name: EXTRA
+          value: "true"
+`,
+				},
+			},
+			validate: func(g *WithT, speaker *appsv1.DaemonSet, _ *appsv1.Deployment) {
+				g.Expect(len(speaker.Spec.Template.Spec.Containers)).To(BeNumerically(">", 1))
+				for _, c := range speaker.Spec.Template.Spec.Containers {
+					if c.Name != "speaker" {
+						continue
+					}
+					g.Expect(c.Image).To(Equal("quay.io/metallb/speaker:v0.0.0"))
+					g.Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "EXTRA", Value: "true"}))
+					g.Expect(len(c.Env)).To(BeNumerically(">", 1))
+				}
+			},
+		},
+		{
+			desc: "json patch adds a host alias",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{
+					Kind:  "Deployment",
+					Name:  "controller",
+					Type:  metallbv1beta1.JSONPatch,
+					Patch: `[{"op": "add", "path": "/spec/template/spec/hostAliases", "value": [{"ip": "10.0.0.1", "hostnames": ["foo"]}]}]`,
+				},
+			},
+			validate: func(g *WithT, _ *appsv1.DaemonSet, controller *appsv1.Deployment) {
+				g.Expect(controller.Spec.Template.Spec.HostAliases).To(Equal([]v1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"foo"}}}))
+			},
+		},
+		{
+			desc: "overrides are applied in order",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{Kind: "Deployment", Name: "controller", Patch: `{"metadata": {"annotations": {"foo": "bar"}}}`},
+				{Kind: "Deployment", Name: "controller", Patch: `{"metadata": {"annotations": {"foo": "baz"}}}`},
+			},
+			validate: func(g *WithT, _ *appsv1.DaemonSet, controller *appsv1.Deployment) {
+				g.Expect(controller.Annotations).To(HaveKeyWithValue("foo", "baz"))
+			},
+		},
+		{
+			desc: "target not found",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{Kind: "Deployment", Name: "speaker", Patch: `{"metadata": {"annotations": {"foo": "bar"}}}`},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "selector change",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{Kind: "DaemonSet", Name: "speaker", Patch: `{"spec": {"selector": {"matchLabels": {"foo": "bar"}}}}`},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "rename",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{Kind: "Deployment", Name: "controller", Type: metallbv1beta1.JSONPatch, Patch: `[{"op": "replace", "path": "/metadata/name", "value": "foo"}]`},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "invalid json patch",
+			overrides: []metallbv1beta1.ObjectOverride{
+				{Kind: "Deployment", Name: "controller", Type: metallbv1beta1.JSONPatch, Patch: `{"spec": {}}`},
+			},
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			chart, err := NewMetalLBChart(metalLBChartPath, metalLBChartName, MetalLBTestNameSpace, nil)
+			g.Expect(err).To(BeNil())
+			metallb := &metallbv1beta1.MetalLB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "metallb",
+					Namespace: MetalLBTestNameSpace,
+				},
+			}
+			objs, err := chart.Objects(defaultEnvConfig, metallb)
+			g.Expect(err).To(BeNil())
+
+			err = ApplyOverrides(objs, test.overrides)
+			if test.shouldErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			speaker := &appsv1.DaemonSet{}
+			controller := &appsv1.Deployment{}
+			for _, obj := range objs {
+				if isSpeakerDaemonSet(obj) {
+					err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), speaker)
+					g.Expect(err).To(BeNil())
+				}
+				if isControllerDeployment(obj) {
+					err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), controller)
+					g.Expect(err).To(BeNil())
+				}
+			}
+			test.validate(g, speaker, controller)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
 	openshiftapiv1 "github.com/openshift/api/operator/v1"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -41,22 +42,63 @@ func cnoSupportsFRRK8s(cno *openshiftconfigv1.ClusterOperator, envConfig params.
 	return false, errors.New("failed to find \"operator\" in network operator versions")
 }
 
-func DeployFRRK8s(ctx context.Context, cli client.Client) error {
+// frrProviderAddedAnnotation marks the network configuration when the FRR
+// routing provider was added by the operator, so that it can be reverted.
+const frrProviderAddedAnnotation = "metallb.io/frr-provider-added"
+
+// DeployFRRK8s asks the cluster network operator to deploy frr-k8s, adding the
+// FRR routing provider to the network configuration. It returns true if the
+// provider was added, false if it was already there.
+func DeployFRRK8s(ctx context.Context, cli client.Client) (bool, error) {
 	network := &openshiftapiv1.Network{}
 	err := cli.Get(ctx, types.NamespacedName{Name: "cluster"}, network)
 	if err != nil {
-		return errors.Wrapf(err, "get openshift network failed")
+		return false, errors.Wrapf(err, "get openshift network failed")
 	}
 	if network.Spec.AdditionalRoutingCapabilities == nil {
 		network.Spec.AdditionalRoutingCapabilities = &openshiftapiv1.AdditionalRoutingCapabilities{}
 	}
 	if slices.Contains(network.Spec.AdditionalRoutingCapabilities.Providers, openshiftapiv1.RoutingCapabilitiesProviderFRR) {
-		return nil
+		return false, nil
 	}
 	network.Spec.AdditionalRoutingCapabilities.Providers = append(network.Spec.AdditionalRoutingCapabilities.Providers, openshiftapiv1.RoutingCapabilitiesProviderFRR)
+	if network.Annotations == nil {
+		network.Annotations = map[string]string{}
+	}
+	network.Annotations[frrProviderAddedAnnotation] = "true"
 	err = cli.Update(ctx, network)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	return true, nil
+}
+
+// RemoveFRRK8s reverts DeployFRRK8s, removing the FRR routing provider from the
+// network configuration. The provider is removed only if it was added by the operator,
+// and is kept when the ovn-kubernetes route advertisements, which require it, are enabled.
+func RemoveFRRK8s(ctx context.Context, cli client.Client) error {
+	network := &openshiftapiv1.Network{}
+	err := cli.Get(ctx, types.NamespacedName{Name: "cluster"}, network)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get openshift network failed")
+	}
+	if network.Annotations[frrProviderAddedAnnotation] != "true" {
+		return nil
+	}
+	if network.Spec.AdditionalRoutingCapabilities != nil && !routeAdvertisementsEnabled(network) {
+		network.Spec.AdditionalRoutingCapabilities.Providers = slices.DeleteFunc(network.Spec.AdditionalRoutingCapabilities.Providers,
+			func(p openshiftapiv1.RoutingCapabilitiesProvider) bool {
+				return p == openshiftapiv1.RoutingCapabilitiesProviderFRR
+			})
+	}
+	delete(network.Annotations, frrProviderAddedAnnotation)
+	return cli.Update(ctx, network)
+}
+
+func routeAdvertisementsEnabled(network *openshiftapiv1.Network) bool {
+	ovnConfig := network.Spec.DefaultNetwork.OVNKubernetesConfig
+	return ovnConfig != nil && ovnConfig.RouteAdvertisements == openshiftapiv1.RouteAdvertisementsEnabled
 }
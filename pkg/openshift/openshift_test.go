@@ -1,10 +1,18 @@
 package openshift
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/metallb/metallb-operator/pkg/params"
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	openshiftapiv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestCnoSupportsFRRK8s(t *testing.T) {
@@ -118,3 +126,87 @@ func TestCnoSupportsFRRK8s(t *testing.T) {
 		})
 	}
 }
+
+func TestDeployAndRemoveFRRK8s(t *testing.T) {
+	tests := []struct {
+		name                string
+		providers           []openshiftapiv1.RoutingCapabilitiesProvider
+		routeAdvertisements openshiftapiv1.RouteAdvertisementsEnablement
+		expectedAfterRm     []openshiftapiv1.RoutingCapabilitiesProvider
+		expectedAfterAdd    []openshiftapiv1.RoutingCapabilitiesProvider
+		expectAnnotations   bool
+	}{
+		{
+			name:              "provider added by the operator",
+			providers:         nil,
+			expectedAfterAdd:  []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+			expectedAfterRm:   []openshiftapiv1.RoutingCapabilitiesProvider{},
+			expectAnnotations: true,
+		},
+		{
+			name:             "provider already there",
+			providers:        []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+			expectedAfterAdd: []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+			expectedAfterRm:  []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+		},
+		{
+			name:                "provider required by the route advertisements",
+			providers:           nil,
+			routeAdvertisements: openshiftapiv1.RouteAdvertisementsEnabled,
+			expectedAfterAdd:    []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+			expectedAfterRm:     []openshiftapiv1.RoutingCapabilitiesProvider{openshiftapiv1.RoutingCapabilitiesProviderFRR},
+			expectAnnotations:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = openshiftapiv1.AddToScheme(scheme)
+			network := &openshiftapiv1.Network{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: openshiftapiv1.NetworkSpec{
+					AdditionalRoutingCapabilities: &openshiftapiv1.AdditionalRoutingCapabilities{
+						Providers: test.providers,
+					},
+					DefaultNetwork: openshiftapiv1.DefaultNetworkDefinition{
+						OVNKubernetesConfig: &openshiftapiv1.OVNKubernetesConfig{
+							RouteAdvertisements: test.routeAdvertisements,
+						},
+					},
+				},
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(network).Build()
+
+			providers := func() []openshiftapiv1.RoutingCapabilitiesProvider {
+				res := &openshiftapiv1.Network{}
+				if err := cli.Get(context.Background(), client.ObjectKeyFromObject(network), res); err != nil {
+					t.Fatalf("failed to get network: %v", err)
+				}
+				if _, ok := res.Annotations[frrProviderAddedAnnotation]; ok != test.expectAnnotations {
+					t.Fatalf("expected annotation %v, got %v", test.expectAnnotations, ok)
+				}
+				return res.Spec.AdditionalRoutingCapabilities.Providers
+			}
+
+			added, err := DeployFRRK8s(context.Background(), cli)
+			if err != nil {
+				t.Fatalf("deploy failed: %v", err)
+			}
+			if added != test.expectAnnotations {
+				t.Fatalf("expected added %v, got %v", test.expectAnnotations, added)
+			}
+			if diff := cmp.Diff(test.expectedAfterAdd, providers()); diff != "" {
+				t.Fatalf("unexpected providers after deploy: %s", diff)
+			}
+
+			test.expectAnnotations = false
+			if err := RemoveFRRK8s(context.Background(), cli); err != nil {
+				t.Fatalf("remove failed: %v", err)
+			}
+			if diff := cmp.Diff(test.expectedAfterRm, providers(), cmpopts.EquateEmpty()); diff != "" {
+				t.Fatalf("unexpected providers after remove: %s", diff)
+			}
+		})
+	}
+}
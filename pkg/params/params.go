@@ -14,6 +14,22 @@ import (
 type ImageInfo struct {
 	Repo string
 	Tag  string
+	// Digest pins the image, for example sha256:0123. When set, the image is
+	// pulled by digest and the tag is informative only.
+	Digest string
+}
+
+// Reference returns the full reference of the image, in the repo[:tag][@digest]
+// form.
+func (i ImageInfo) Reference() string {
+	res := i.Repo
+	if i.Tag != "" {
+		res += ":" + i.Tag
+	}
+	if i.Digest != "" {
+		res += "@" + i.Digest
+	}
+	return res
 }
 
 func BGPType(m *v1beta1.MetalLB, env EnvConfig) v1beta1.BGPType {
@@ -29,6 +45,82 @@ func BGPType(m *v1beta1.MetalLB, env EnvConfig) v1beta1.BGPType {
 	return v1beta1.FRRMode
 }
 
+// FRRK8sNamespace returns the namespace where frr-k8s is expected to run when
+// deployed externally to the operator.
+func FRRK8sNamespace(m *v1beta1.MetalLB, env EnvConfig) string {
+	if m.Spec.FRRK8SConfig != nil && m.Spec.FRRK8SConfig.Namespace != "" {
+		return m.Spec.FRRK8SConfig.Namespace
+	}
+	return env.FRRK8sExternalNamespace
+}
+
+// EffectiveSpec returns the values in effect for the fields of the spec whose
+// defaults depend on the platform and on the operator configuration.
+func EffectiveSpec(m *v1beta1.MetalLB, env EnvConfig) *v1beta1.EffectiveSpec {
+	res := &v1beta1.EffectiveSpec{BGPBackend: BGPType(m, env)}
+	if res.BGPBackend == v1beta1.FRRK8sExternalMode {
+		res.FRRK8sNamespace = FRRK8sNamespace(m, env)
+	}
+	return res
+}
+
+// DefaultMemberlistSecretName is the name of the Secret holding the memberlist
+// key when not overridden in the MetalLB resource.
+const DefaultMemberlistSecretName = "metallb-memberlist"
+
+// MemberlistEnabled tells if the speakers must form a memberlist cluster.
+func MemberlistEnabled(m *v1beta1.MetalLB) bool {
+	if m.Spec.Memberlist != nil && m.Spec.Memberlist.Enabled != nil {
+		return *m.Spec.Memberlist.Enabled
+	}
+	return true
+}
+
+// MemberlistBindPort returns the port the speakers use for the memberlist
+// traffic.
+func MemberlistBindPort(m *v1beta1.MetalLB, env EnvConfig) int {
+	if m.Spec.Memberlist != nil && m.Spec.Memberlist.BindPort != nil {
+		return int(*m.Spec.Memberlist.BindPort)
+	}
+	return env.MLBindPort
+}
+
+// MemberlistSecretName returns the name of the Secret holding the memberlist
+// key.
+func MemberlistSecretName(m *v1beta1.MetalLB) string {
+	if m.Spec.Memberlist != nil && m.Spec.Memberlist.SecretName != "" {
+		return m.Spec.Memberlist.SecretName
+	}
+	return DefaultMemberlistSecretName
+}
+
+// ServiceMonitorsEnabled tells if ServiceMonitors must be deployed to scrape
+// the metrics.
+func ServiceMonitorsEnabled(m *v1beta1.MetalLB, env EnvConfig) bool {
+	if m.Spec.Monitoring != nil && m.Spec.Monitoring.Type != "" {
+		return m.Spec.Monitoring.Type == v1beta1.ServiceMonitorType
+	}
+	return env.DeployServiceMonitors
+}
+
+// PodMonitorsEnabled tells if PodMonitors must be deployed to scrape the
+// metrics.
+func PodMonitorsEnabled(m *v1beta1.MetalLB, env EnvConfig) bool {
+	if m.Spec.Monitoring != nil && m.Spec.Monitoring.Type != "" {
+		return m.Spec.Monitoring.Type == v1beta1.PodMonitorType
+	}
+	return env.DeployPodMonitors
+}
+
+// AlertsEnabled tells if the PrometheusRules with the MetalLB alerts must be
+// deployed.
+func AlertsEnabled(m *v1beta1.MetalLB, env EnvConfig) bool {
+	if m.Spec.Monitoring != nil && m.Spec.Monitoring.Alerts != nil && m.Spec.Monitoring.Alerts.Enabled != nil {
+		return *m.Spec.Monitoring.Alerts.Enabled
+	}
+	return env.DeployPrometheusRules
+}
+
 type EnvConfig struct {
 	Namespace                  string
 	FRRK8sExternalNamespace    string
@@ -50,6 +142,7 @@ type EnvConfig struct {
 	TLSMinVersion              string
 	DeployPodMonitors          bool
 	DeployServiceMonitors      bool
+	DeployPrometheusRules      bool
 	DisableNetworkPolicies     bool
 	IsOpenshift                bool
 	MustDeployFRRK8sFromCNO    bool
@@ -120,6 +213,9 @@ func FromEnvironment(isOpenshift bool) (EnvConfig, error) {
 	if os.Getenv("DEPLOY_SERVICEMONITORS") == "true" {
 		res.DeployServiceMonitors = true
 	}
+	if os.Getenv("DEPLOY_PROMETHEUSRULES") == "true" {
+		res.DeployPrometheusRules = true
+	}
 	if os.Getenv("DISABLE_NETWORK_POLICIES") == "true" {
 		res.DisableNetworkPolicies = true
 	}
@@ -164,6 +260,10 @@ func imageFromEnv(imageEnv string) (ImageInfo, error) {
 	if !found {
 		return res, fmt.Errorf("%s environment value not set", imageEnv)
 	}
+	// The digest goes after the tag, and contains a ":" itself.
+	if pos := strings.Index(value, "@"); pos >= 0 {
+		value, res.Digest = value[:pos], value[pos+1:]
+	}
 	res.Repo, res.Tag = getImageNameTag(value)
 	return res, nil
 }
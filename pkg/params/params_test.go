@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/metallb/metallb-operator/api/v1beta1"
 )
 
 func TestFromEnvironment(t *testing.T) {
@@ -53,6 +54,7 @@ func TestFromEnvironment(t *testing.T) {
 			setup: func() {
 				setBasics()
 				_ = os.Setenv("DEPLOY_SERVICEMONITORS", "true")
+				_ = os.Setenv("DEPLOY_PROMETHEUSRULES", "true")
 
 				_ = os.Setenv("MEMBER_LIST_BIND_PORT", "1111")
 				_ = os.Setenv("FRR_METRICS_PORT", "2222")
@@ -90,6 +92,7 @@ func TestFromEnvironment(t *testing.T) {
 				SecureFRRK8sMetricsPort:    7777,
 				SecureFRRK8sFRRMetricsPort: 8888,
 				DeployServiceMonitors:      true,
+				DeployPrometheusRules:      true,
 			},
 		},
 		{
@@ -163,6 +166,7 @@ func unset() {
 	_ = os.Unsetenv("FRRK8S_METRICS_PORT")
 	_ = os.Unsetenv("DEPLOY_PODMONITORS")
 	_ = os.Unsetenv("DEPLOY_SERVICEMONITORS")
+	_ = os.Unsetenv("DEPLOY_PROMETHEUSRULES")
 	_ = os.Unsetenv("DISABLE_NETWORK_POLICIES")
 }
 
@@ -173,3 +177,165 @@ func setBasics() {
 	_ = os.Setenv("FRR_IMAGE", "test-frr-image:3")
 	_ = os.Setenv("FRRK8S_IMAGE", "test-frrk8s-image:5")
 }
+
+func TestFRRK8sNamespace(t *testing.T) {
+	env := EnvConfig{FRRK8sExternalNamespace: "env-namespace"}
+
+	m := &v1beta1.MetalLB{}
+	if ns := FRRK8sNamespace(m, env); ns != "env-namespace" {
+		t.Errorf("expected namespace from env, got %s", ns)
+	}
+
+	m.Spec.FRRK8SConfig = &v1beta1.FRRK8SConfig{Namespace: "spec-namespace"}
+	if ns := FRRK8sNamespace(m, env); ns != "spec-namespace" {
+		t.Errorf("expected namespace from spec, got %s", ns)
+	}
+}
+
+func TestEffectiveSpec(t *testing.T) {
+	tests := []struct {
+		desc     string
+		spec     v1beta1.MetalLBSpec
+		env      EnvConfig
+		expected v1beta1.EffectiveSpec
+	}{
+		{
+			desc:     "vanilla default",
+			expected: v1beta1.EffectiveSpec{BGPBackend: v1beta1.FRRMode},
+		},
+		{
+			desc:     "openshift default",
+			env:      EnvConfig{IsOpenshift: true},
+			expected: v1beta1.EffectiveSpec{BGPBackend: v1beta1.FRRK8sMode},
+		},
+		{
+			desc: "openshift frr-k8s from the CNO",
+			env:  EnvConfig{IsOpenshift: true, MustDeployFRRK8sFromCNO: true, FRRK8sExternalNamespace: "openshift-frr-k8s"},
+			expected: v1beta1.EffectiveSpec{
+				BGPBackend:      v1beta1.FRRK8sExternalMode,
+				FRRK8sNamespace: "openshift-frr-k8s",
+			},
+		},
+		{
+			desc:     "backend from the spec",
+			spec:     v1beta1.MetalLBSpec{BGPBackend: v1beta1.NativeMode},
+			env:      EnvConfig{IsOpenshift: true, FRRK8sExternalNamespace: "openshift-frr-k8s"},
+			expected: v1beta1.EffectiveSpec{BGPBackend: v1beta1.NativeMode},
+		},
+		{
+			desc: "external namespace from the spec",
+			spec: v1beta1.MetalLBSpec{
+				BGPBackend:   v1beta1.FRRK8sExternalMode,
+				FRRK8SConfig: &v1beta1.FRRK8SConfig{Namespace: "spec-namespace"},
+			},
+			env: EnvConfig{FRRK8sExternalNamespace: "env-namespace"},
+			expected: v1beta1.EffectiveSpec{
+				BGPBackend:      v1beta1.FRRK8sExternalMode,
+				FRRK8sNamespace: "spec-namespace",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			effective := EffectiveSpec(&v1beta1.MetalLB{Spec: test.spec}, test.env)
+			if *effective != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, *effective)
+			}
+		})
+	}
+}
+
+func TestImageFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ImageInfo
+	}{
+		{
+			value:    "quay.io/metallb/speaker:v0.14.8",
+			expected: ImageInfo{Repo: "quay.io/metallb/speaker", Tag: "v0.14.8"},
+		},
+		{
+			value:    "quay.io:5000/metallb/speaker",
+			expected: ImageInfo{Repo: "quay.io:5000/metallb/speaker"},
+		},
+		{
+			value:    "quay.io/metallb/speaker@sha256:0123456789abcdef",
+			expected: ImageInfo{Repo: "quay.io/metallb/speaker", Digest: "sha256:0123456789abcdef"},
+		},
+		{
+			value:    "quay.io:5000/metallb/speaker:v0.14.8@sha256:0123456789abcdef",
+			expected: ImageInfo{Repo: "quay.io:5000/metallb/speaker", Tag: "v0.14.8", Digest: "sha256:0123456789abcdef"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv("SPEAKER_IMAGE", test.value)
+			res, err := imageFromEnv("SPEAKER_IMAGE")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if res != test.expected {
+				t.Errorf("res different from expected, %s", cmp.Diff(res, test.expected))
+			}
+			if res.Reference() != test.value {
+				t.Errorf("expected reference %s, got %s", test.value, res.Reference())
+			}
+		})
+	}
+}
+
+func TestMonitorsEnabled(t *testing.T) {
+	tests := []struct {
+		desc            string
+		monitorType     v1beta1.MonitorType
+		env             EnvConfig
+		serviceMonitors bool
+		podMonitors     bool
+	}{
+		{
+			desc: "no monitors",
+		},
+		{
+			desc:        "pod monitors from env",
+			env:         EnvConfig{DeployPodMonitors: true},
+			podMonitors: true,
+		},
+		{
+			desc:            "service monitors from env",
+			env:             EnvConfig{DeployServiceMonitors: true},
+			serviceMonitors: true,
+		},
+		{
+			desc:            "both from env",
+			env:             EnvConfig{DeployServiceMonitors: true, DeployPodMonitors: true},
+			serviceMonitors: true,
+			podMonitors:     true,
+		},
+		{
+			desc:            "service monitors from spec",
+			monitorType:     v1beta1.ServiceMonitorType,
+			env:             EnvConfig{DeployPodMonitors: true},
+			serviceMonitors: true,
+		},
+		{
+			desc:        "none from spec",
+			monitorType: v1beta1.NoMonitorType,
+			env:         EnvConfig{DeployServiceMonitors: true, DeployPodMonitors: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			m := &v1beta1.MetalLB{}
+			if test.monitorType != "" {
+				m.Spec.Monitoring = &v1beta1.MonitoringConfig{Type: test.monitorType}
+			}
+			if res := ServiceMonitorsEnabled(m, test.env); res != test.serviceMonitors {
+				t.Errorf("expected service monitors %v, got %v", test.serviceMonitors, res)
+			}
+			if res := PodMonitorsEnabled(m, test.env); res != test.podMonitors {
+				t.Errorf("expected pod monitors %v, got %v", test.podMonitors, res)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ limitations under the License.
 package platform
 
 import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -102,3 +103,21 @@ func (pv k8SBasedPlatformVersioner) getPlatformInfo(client discovery.DiscoveryIn
 	log.Info(info.String())
 	return info, nil
 }
+
+// ServedKinds returns the kinds served by the API server for the given group
+// version, e.g. "monitoring.coreos.com/v1". No kinds are returned when the group
+// version is not served, for example when its CRDs are not installed.
+func ServedKinds(client discovery.DiscoveryInterface, groupVersion string) (map[string]bool, error) {
+	kinds := map[string]bool{}
+	resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return kinds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resources.APIResources {
+		kinds[r.Kind] = true
+	}
+	return kinds, nil
+}
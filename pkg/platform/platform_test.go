@@ -0,0 +1,59 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package platform
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestServedKinds(t *testing.T) {
+	tests := []struct {
+		desc      string
+		resources []*metav1.APIResourceList
+		expected  map[string]bool
+	}{
+		{
+			desc:     "group version not served",
+			expected: map[string]bool{},
+		},
+		{
+			desc: "some kinds served",
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "monitoring.coreos.com/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "servicemonitors", Kind: "ServiceMonitor"},
+						{Name: "prometheusrules", Kind: "PrometheusRule"},
+					},
+				},
+			},
+			expected: map[string]bool{"ServiceMonitor": true, "PrometheusRule": true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: test.resources}}
+			kinds, err := ServedKinds(client, "monitoring.coreos.com/v1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(kinds).To(Equal(test.expected))
+		})
+	}
+}
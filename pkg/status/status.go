@@ -2,13 +2,23 @@ package status
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,19 +37,164 @@ func (e MetalLBResourcesNotReadyError) Is(target error) bool {
 
 type DeploymentNotReadyError struct{}
 
+// ExternalFRRK8sNotReadyError is returned when frr-k8s is expected to be
+// deployed outside of the operator but it is not available.
+type ExternalFRRK8sNotReadyError struct {
+	Message string
+}
+
+func (e ExternalFRRK8sNotReadyError) Error() string { return e.Message }
+
 const (
 	ConditionAvailable   = "Available"
 	ConditionProgressing = "Progressing"
 	ConditionDegraded    = "Degraded"
 	ConditionUpgradeable = "Upgradeable"
+	// ConditionPaused is set while the reconciliation of the operands is paused.
+	ConditionPaused = "Paused"
+	// ConditionPreview is set while the operator runs in preview apply mode.
+	ConditionPreview = "Preview"
+)
+
+// Per operand conditions, reporting the readiness of each of the pieces
+// deployed by the operator.
+const (
+	ConditionSpeakerReady    = "SpeakerReady"
+	ConditionControllerReady = "ControllerReady"
+	ConditionFRRK8sReady     = "FRRK8sReady"
+	ConditionWebhookReady    = "WebhookReady"
+	ConditionMonitoringReady = "MonitoringReady"
+	// ConditionMonitoringUnavailable is set while some of the monitoring objects
+	// are not applied because their CRDs are not installed.
+	ConditionMonitoringUnavailable = "MonitoringUnavailable"
+)
+
+var componentConditionTypes = []string{
+	ConditionSpeakerReady,
+	ConditionControllerReady,
+	ConditionFRRK8sReady,
+	ConditionWebhookReady,
+	ConditionMonitoringReady,
+	ConditionMonitoringUnavailable,
+}
+
+const (
+	reasonReady    = "Ready"
+	reasonNotReady = "NotReady"
+
+	ReasonExternalFRRK8sNotReady = "ExternalFRRK8sNotReady"
+	ReasonReconcilePaused        = "ReconcilePaused"
+	ReasonChangesPending         = "ChangesPending"
+	ReasonNoChanges              = "NoChanges"
+	ReasonCRDsNotInstalled       = "CRDsNotInstalled"
 )
 
-func Update(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta1.MetalLB, condition string, reason string, message string) error {
-	conditions := getConditions(condition, reason, message)
-	if equality.Semantic.DeepEqual(conditions, metallb.Status.Conditions) {
+const externalFRRK8sSelector = "app.kubernetes.io/component=frr-k8s"
+
+const controllerDeploymentName = "controller"
+
+type workload struct {
+	kind string
+	name string
+}
+
+// operandWorkloads are the workloads whose container images are reported in
+// the status.
+var operandWorkloads = []workload{
+	{kind: "Deployment", name: controllerDeploymentName},
+	{kind: "DaemonSet", name: "speaker"},
+	{kind: "DaemonSet", name: "frr-k8s"},
+	{kind: "Deployment", name: "statuscleaner"},
+}
+
+// externalFRRK8sCRDs are the frr-k8s CRDs MetalLB relies on when frr-k8s
+// is deployed externally.
+var externalFRRK8sCRDs = []string{
+	"frrconfigurations.frrk8s.metallb.io",
+	"frrnodestates.frrk8s.metallb.io",
+}
+
+// Component groups the rendered objects that back one of the per operand conditions.
+type Component struct {
+	Condition string
+	Objects   []*unstructured.Unstructured
+}
+
+// Update sets the global condition on the MetalLB status, together with the given
+// per operand conditions. When no component conditions are passed, the ones already
+// present in the status are left untouched, and so is the effective spec when nil.
+func Update(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta1.MetalLB, effective *metallbv1beta1.EffectiveSpec, condition string, reason string, message string, components ...metav1.Condition) error {
+	updated := metallb.Status.DeepCopy()
+	updated.ObservedGeneration = metallb.Generation
+	if effective != nil {
+		updated.EffectiveSpec = effective
+	}
+	for _, c := range getConditions(condition, reason, message) {
+		c.ObservedGeneration = metallb.Generation
+		meta.SetStatusCondition(&updated.Conditions, c)
+	}
+	if len(components) > 0 {
+		setComponentConditions(&updated.Conditions, components, metallb.Generation)
+		ready, err := controllerReadyReplicas(ctx, client, metallb.Namespace)
+		if err != nil {
+			return err
+		}
+		updated.ControllerReadyReplicas = ready
+		images, err := operandImages(ctx, client, metallb.Namespace)
+		if err != nil {
+			return err
+		}
+		updated.Images = images
+	}
+	// The operands are being reconciled, so the reconciliation is neither paused
+	// nor previewed anymore.
+	meta.RemoveStatusCondition(&updated.Conditions, ConditionPaused)
+	meta.RemoveStatusCondition(&updated.Conditions, ConditionPreview)
+	updated.PendingChanges = nil
+	return updateStatus(ctx, client, metallb, updated)
+}
+
+// UpdatePreview records the changes that would be applied to the operands,
+// leaving the other conditions as they were before entering the preview mode.
+func UpdatePreview(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta1.MetalLB, changes []metallbv1beta1.OperandChange) error {
+	updated := metallb.Status.DeepCopy()
+	updated.ObservedGeneration = metallb.Generation
+	updated.PendingChanges = changes
+	condition := metav1.Condition{
+		Type:               ConditionPreview,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonNoChanges,
+		Message:            "No changes to the operands",
+		ObservedGeneration: metallb.Generation,
+	}
+	if len(changes) > 0 {
+		condition.Reason = ReasonChangesPending
+		condition.Message = fmt.Sprintf("%d operand objects would be changed", len(changes))
+	}
+	meta.SetStatusCondition(&updated.Conditions, condition)
+	return updateStatus(ctx, client, metallb, updated)
+}
+
+// UpdatePaused sets the Paused condition on the MetalLB status, leaving the
+// other conditions as they were when the reconciliation was paused.
+func UpdatePaused(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta1.MetalLB, message string) error {
+	updated := metallb.Status.DeepCopy()
+	updated.ObservedGeneration = metallb.Generation
+	meta.SetStatusCondition(&updated.Conditions, metav1.Condition{
+		Type:               ConditionPaused,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonReconcilePaused,
+		Message:            message,
+		ObservedGeneration: metallb.Generation,
+	})
+	return updateStatus(ctx, client, metallb, updated)
+}
+
+func updateStatus(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta1.MetalLB, updated *metallbv1beta1.MetalLBStatus) error {
+	if equality.Semantic.DeepEqual(*updated, metallb.Status) {
 		return nil
 	}
-	metallb.Status.Conditions = getConditions(condition, reason, message)
+	metallb.Status = *updated
 
 	if err := client.Status().Update(ctx, metallb); err != nil {
 		return errors.Wrapf(err, "could not update status for object %+v", metallb)
@@ -47,6 +202,93 @@ func Update(ctx context.Context, client k8sclient.Client, metallb *metallbv1beta
 	return nil
 }
 
+// controllerReadyReplicas returns the number of ready replicas of the MetalLB
+// controller deployed in the given namespace.
+func controllerReadyReplicas(ctx context.Context, client k8sclient.Client, namespace string) (int32, error) {
+	deployment := &appsv1.Deployment{}
+	err := client.Get(ctx, types.NamespacedName{Name: controllerDeploymentName, Namespace: namespace}, deployment)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return deployment.Status.ReadyReplicas, nil
+}
+
+// operandImages returns the images of the containers of the operands deployed
+// in the given namespace, together with the image IDs reported by their pods.
+func operandImages(ctx context.Context, client k8sclient.Client, namespace string) ([]metallbv1beta1.OperandImage, error) {
+	var res []metallbv1beta1.OperandImage
+	for _, w := range operandWorkloads {
+		template, selector, err := workloadPodTemplate(ctx, client, namespace, w)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		podSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		pods := &corev1.PodList{}
+		err = client.List(ctx, pods, k8sclient.InNamespace(namespace), k8sclient.MatchingLabelsSelector{Selector: podSelector})
+		if err != nil {
+			return nil, err
+		}
+		imageIDs := map[string][]string{}
+		for _, pod := range pods.Items {
+			for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				if s.ImageID != "" && !slices.Contains(imageIDs[s.Name], s.ImageID) {
+					imageIDs[s.Name] = append(imageIDs[s.Name], s.ImageID)
+				}
+			}
+		}
+		for _, c := range append(template.Spec.InitContainers, template.Spec.Containers...) {
+			ids := imageIDs[c.Name]
+			slices.Sort(ids)
+			res = append(res, metallbv1beta1.OperandImage{
+				Workload:  fmt.Sprintf("%s/%s", w.kind, w.name),
+				Container: c.Name,
+				Image:     c.Image,
+				ImageIDs:  ids,
+			})
+		}
+	}
+	return res, nil
+}
+
+func workloadPodTemplate(ctx context.Context, client k8sclient.Client, namespace string, w workload) (corev1.PodTemplateSpec, *metav1.LabelSelector, error) {
+	key := types.NamespacedName{Name: w.name, Namespace: namespace}
+	if w.kind == "DaemonSet" {
+		ds := &appsv1.DaemonSet{}
+		if err := client.Get(ctx, key, ds); err != nil {
+			return corev1.PodTemplateSpec{}, nil, err
+		}
+		return ds.Spec.Template, ds.Spec.Selector, nil
+	}
+	deployment := &appsv1.Deployment{}
+	if err := client.Get(ctx, key, deployment); err != nil {
+		return corev1.PodTemplateSpec{}, nil, err
+	}
+	return deployment.Spec.Template, deployment.Spec.Selector, nil
+}
+
+// setComponentConditions replaces the per operand conditions with the given ones,
+// dropping those related to operands that are not deployed anymore.
+func setComponentConditions(conditions *[]metav1.Condition, components []metav1.Condition, generation int64) {
+	for _, t := range componentConditionTypes {
+		if meta.FindStatusCondition(components, t) == nil {
+			meta.RemoveStatusCondition(conditions, t)
+		}
+	}
+	for _, c := range components {
+		c.ObservedGeneration = generation
+		meta.SetStatusCondition(conditions, c)
+	}
+}
+
 func getConditions(condition string, reason string, message string) []metav1.Condition {
 	conditions := getBaseConditions()
 	switch condition {
@@ -95,46 +337,219 @@ func getBaseConditions() []metav1.Condition {
 	}
 }
 
-func IsMetalLBAvailable(ctx context.Context, client k8sclient.Client, namespace string) error {
-	ds := &appsv1.DaemonSet{}
-	err := client.Get(ctx, types.NamespacedName{Name: "speaker", Namespace: namespace}, ds)
+// Components groups the given rendered objects by the per operand condition
+// they contribute to. Objects not related to any condition are ignored.
+func Components(objs []*unstructured.Unstructured) []Component {
+	byCondition := map[string][]*unstructured.Unstructured{}
+	for _, obj := range objs {
+		condition := componentConditionFor(obj)
+		if condition == "" {
+			continue
+		}
+		byCondition[condition] = append(byCondition[condition], obj)
+	}
+	res := []Component{}
+	for _, t := range componentConditionTypes {
+		if len(byCondition[t]) == 0 {
+			continue
+		}
+		res = append(res, Component{Condition: t, Objects: byCondition[t]})
+	}
+	return res
+}
+
+func componentConditionFor(obj *unstructured.Unstructured) string {
+	switch {
+	case obj.GetKind() == "DaemonSet" && obj.GetName() == "speaker":
+		return ConditionSpeakerReady
+	case obj.GetKind() == "Deployment" && obj.GetName() == controllerDeploymentName:
+		return ConditionControllerReady
+	case obj.GetKind() == "DaemonSet" && obj.GetName() == "frr-k8s":
+		return ConditionFRRK8sReady
+	case obj.GetKind() == "Deployment" && obj.GetName() == "statuscleaner":
+		return ConditionWebhookReady
+	case obj.GetKind() == "Service" && obj.GetName() == "frr-k8s-webhook-service":
+		return ConditionWebhookReady
+	case obj.GetKind() == "ServiceMonitor" || obj.GetKind() == "PodMonitor":
+		return ConditionMonitoringReady
+	}
+	return ""
+}
+
+// IsMetalLBAvailable checks the readiness of the objects backing each component and
+// returns one condition per component, listing the objects that are not ready.
+// If any of them is not ready, a MetalLBResourcesNotReadyError naming the
+// blocking components is returned together with the conditions.
+func IsMetalLBAvailable(ctx context.Context, client k8sclient.Client, components []Component) ([]metav1.Condition, error) {
+	conditions := []metav1.Condition{}
+	notReady := []string{}
+	for _, c := range components {
+		condition := metav1.Condition{
+			Type:   c.Condition,
+			Status: metav1.ConditionTrue,
+			Reason: reasonReady,
+		}
+		messages := []string{}
+		for _, obj := range c.Objects {
+			err := isObjectReady(ctx, client, obj)
+			if _, ok := err.(MetalLBResourcesNotReadyError); ok {
+				messages = append(messages, err.Error())
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(messages) > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = reasonNotReady
+			condition.Message = strings.Join(messages, "; ")
+			notReady = append(notReady, fmt.Sprintf("%s: %s", c.Condition, condition.Message))
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(notReady) > 0 {
+		return conditions, MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB components not ready: %s", strings.Join(notReady, "; "))}
+	}
+	return conditions, nil
+}
+
+// MonitoringUnavailable returns the condition reporting the monitoring kinds that
+// are skipped because their CRDs are not installed.
+func MonitoringUnavailable(kinds []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    ConditionMonitoringUnavailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonCRDsNotInstalled,
+		Message: fmt.Sprintf("not deploying %s, the monitoring.coreos.com CRDs are not installed", strings.Join(kinds, ", ")),
+	}
+}
+
+// IsExternalFRRK8sAvailable checks that frr-k8s, deployed outside of the operator in
+// the given namespace, has its CRDs installed and a ready daemonset. The returned
+// condition reports the outcome as FRRK8sReady, and an ExternalFRRK8sNotReadyError
+// is returned if frr-k8s is not usable.
+func IsExternalFRRK8sAvailable(ctx context.Context, client k8sclient.Client, namespace string) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:   ConditionFRRK8sReady,
+		Status: metav1.ConditionTrue,
+		Reason: reasonReady,
+	}
+	err := isExternalFRRK8sReady(ctx, client, namespace)
+	if notReady, ok := err.(ExternalFRRK8sNotReadyError); ok {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonExternalFRRK8sNotReady
+		condition.Message = notReady.Message
+		return condition, err
+	}
 	if err != nil {
-		return err
+		return metav1.Condition{}, err
 	}
-	if err := isDaemonSetReady(ds); err != nil {
-		return err
+	return condition, nil
+}
+
+func isExternalFRRK8sReady(ctx context.Context, client k8sclient.Client, namespace string) error {
+	for _, name := range externalFRRK8sCRDs {
+		// Only the metadata of the CRDs is needed, so that they are not
+		// cached as full objects.
+		crd := &metav1.PartialObjectMetadata{}
+		crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		err := client.Get(ctx, types.NamespacedName{Name: name}, crd)
+		if apierrors.IsNotFound(err) {
+			return ExternalFRRK8sNotReadyError{Message: fmt.Sprintf("frr-k8s crd %s not installed", name)}
+		}
+		if err != nil {
+			return err
+		}
 	}
-	deployment := &appsv1.Deployment{}
-	err = client.Get(ctx, types.NamespacedName{Name: "controller", Namespace: namespace}, deployment)
+
+	selector, err := labels.Parse(externalFRRK8sSelector)
 	if err != nil {
 		return err
 	}
-	if err := isDeploymentReady(deployment); err != nil {
+	daemonSets := &appsv1.DaemonSetList{}
+	err = client.List(ctx, daemonSets, k8sclient.InNamespace(namespace), k8sclient.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
 		return err
 	}
+	if len(daemonSets.Items) == 0 {
+		return ExternalFRRK8sNotReadyError{Message: fmt.Sprintf("frr-k8s daemonset not found in namespace %s", namespace)}
+	}
+	for i := range daemonSets.Items {
+		if err := isDaemonSetReady(&daemonSets.Items[i]); err != nil {
+			return ExternalFRRK8sNotReadyError{Message: fmt.Sprintf("namespace %s: %s", namespace, strings.TrimPrefix(err.Error(), "MetalLB "))}
+		}
+	}
 	return nil
 }
 
+func isObjectReady(ctx context.Context, client k8sclient.Client, obj *unstructured.Unstructured) error {
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	var err error
+	switch obj.GetKind() {
+	case "DaemonSet":
+		ds := &appsv1.DaemonSet{}
+		err = client.Get(ctx, key, ds)
+		if err == nil {
+			return isDaemonSetReady(ds)
+		}
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		err = client.Get(ctx, key, deployment)
+		if err == nil {
+			return isDeploymentReady(deployment)
+		}
+	case "Service":
+		return hasReadyEndpoints(ctx, client, key)
+	default:
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		err = client.Get(ctx, key, existing)
+	}
+	if apierrors.IsNotFound(err) {
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s %s not found", strings.ToLower(obj.GetKind()), obj.GetName())}
+	}
+	return err
+}
+
 func isDaemonSetReady(ds *appsv1.DaemonSet) error {
 	if ds.Generation != ds.Status.ObservedGeneration {
-		return MetalLBResourcesNotReadyError{Message: "MetalLB speaker daemonset status is out of date"}
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s daemonset status is out of date", ds.Name)}
 	}
 	if ds.Status.DesiredNumberScheduled != ds.Status.CurrentNumberScheduled || ds.Status.DesiredNumberScheduled != ds.Status.NumberReady {
-		return MetalLBResourcesNotReadyError{Message: "MetalLB speaker daemonset not ready"}
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s daemonset not ready, %d/%d pods ready", ds.Name, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)}
 	}
 	if ds.Status.DesiredNumberScheduled != ds.Status.UpdatedNumberScheduled {
-		return MetalLBResourcesNotReadyError{Message: "MetalLB speaker daemonset not ready"}
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s daemonset not ready, %d/%d pods updated", ds.Name, ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)}
 	}
 	return nil
 }
 
 func isDeploymentReady(deployment *appsv1.Deployment) error {
 	if deployment.Generation != deployment.Status.ObservedGeneration {
-		return MetalLBResourcesNotReadyError{Message: "MetalLB controller deployment status is out of date"}
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s deployment status is out of date", deployment.Name)}
 	}
 	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
 	if deployment.Status.ReadyReplicas != replicas || deployment.Status.UpdatedReplicas != replicas {
-		return MetalLBResourcesNotReadyError{Message: "MetalLB controller deployment not ready"}
+		return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s deployment not ready, %d/%d replicas ready", deployment.Name, deployment.Status.ReadyReplicas, replicas)}
 	}
 	return nil
 }
+
+// hasReadyEndpoints checks that the service is backed by at least one ready endpoint,
+// which is what the api server needs in order to reach a webhook.
+func hasReadyEndpoints(ctx context.Context, client k8sclient.Client, svc types.NamespacedName) error {
+	slices := &discoveryv1.EndpointSliceList{}
+	err := client.List(ctx, slices, k8sclient.InNamespace(svc.Namespace), k8sclient.MatchingLabels{discoveryv1.LabelServiceName: svc.Name})
+	if err != nil {
+		return err
+	}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			if ptr.Deref(ep.Conditions.Ready, true) {
+				return nil
+			}
+		}
+	}
+	return MetalLBResourcesNotReadyError{Message: fmt.Sprintf("MetalLB %s service has no ready endpoints", svc.Name)}
+}
@@ -2,11 +2,18 @@ package status
 
 import (
 	"context"
+	"slices"
 	"testing"
 
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -99,7 +106,7 @@ func TestIsMetalLBAvailable(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewGomegaWithT(t)
 			client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(tt.objects...).Build()
-			err := IsMetalLBAvailable(context.Background(), client, "test-ns")
+			_, err := IsMetalLBAvailable(context.Background(), client, Components(renderedObjects()))
 			if tt.expectErr {
 				g.Expect(err).To(HaveOccurred())
 				if tt.isNotReady {
@@ -113,6 +120,290 @@ func TestIsMetalLBAvailable(t *testing.T) {
 	}
 }
 
+func TestIsMetalLBAvailableFRRK8s(t *testing.T) {
+	frrk8sObjects := append(renderedObjects(),
+		renderedObject("apps/v1", "DaemonSet", "frr-k8s"),
+		renderedObject("apps/v1", "Deployment", "statuscleaner"),
+		renderedObject("v1", "Service", "frr-k8s-webhook-service"),
+	)
+	tests := []struct {
+		name         string
+		objects      []k8sclient.Object
+		notReady     []string
+		errContains  string
+		msgContains  string
+		msgCondition string
+	}{
+		{
+			name:    "all ready",
+			objects: []k8sclient.Object{newReadySpeaker(), newReadyController(), newReadyFRRK8s(), newReadyStatusCleaner(), newWebhookEndpoints(true)},
+		},
+		{
+			name: "frr-k8s daemonset not ready",
+			objects: func() []k8sclient.Object {
+				ds := newReadyFRRK8s()
+				ds.Status.NumberReady = 1
+				return []k8sclient.Object{newReadySpeaker(), newReadyController(), ds, newReadyStatusCleaner(), newWebhookEndpoints(true)}
+			}(),
+			notReady:     []string{ConditionFRRK8sReady},
+			errContains:  ConditionFRRK8sReady,
+			msgCondition: ConditionFRRK8sReady,
+			msgContains:  "frr-k8s daemonset not ready, 1/3 pods ready",
+		},
+		{
+			name:         "statuscleaner missing",
+			objects:      []k8sclient.Object{newReadySpeaker(), newReadyController(), newReadyFRRK8s(), newWebhookEndpoints(true)},
+			notReady:     []string{ConditionWebhookReady},
+			errContains:  ConditionWebhookReady,
+			msgCondition: ConditionWebhookReady,
+			msgContains:  "statuscleaner not found",
+		},
+		{
+			name:         "webhook service without ready endpoints",
+			objects:      []k8sclient.Object{newReadySpeaker(), newReadyController(), newReadyFRRK8s(), newReadyStatusCleaner(), newWebhookEndpoints(false)},
+			notReady:     []string{ConditionWebhookReady},
+			errContains:  ConditionWebhookReady,
+			msgCondition: ConditionWebhookReady,
+			msgContains:  "frr-k8s-webhook-service service has no ready endpoints",
+		},
+		{
+			name: "multiple components not ready",
+			objects: func() []k8sclient.Object {
+				ds := newReadyFRRK8s()
+				ds.Status.NumberReady = 0
+				return []k8sclient.Object{newReadySpeaker(), newReadyController(), ds}
+			}(),
+			notReady:     []string{ConditionFRRK8sReady, ConditionWebhookReady},
+			errContains:  "FRRK8sReady: MetalLB frr-k8s daemonset not ready, 0/3 pods ready; WebhookReady: ",
+			msgCondition: ConditionWebhookReady,
+			msgContains:  "statuscleaner not found; MetalLB frr-k8s-webhook-service service has no ready endpoints",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(tt.objects...).Build()
+			conditions, err := IsMetalLBAvailable(context.Background(), client, Components(frrk8sObjects))
+			g.Expect(conditions).To(HaveLen(4))
+			if len(tt.notReady) == 0 {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(BeAssignableToTypeOf(MetalLBResourcesNotReadyError{}))
+			g.Expect(err.Error()).To(ContainSubstring(tt.errContains))
+			for _, c := range conditions {
+				if slices.Contains(tt.notReady, c.Type) {
+					g.Expect(c.Status).To(Equal(metav1.ConditionFalse), c.Type)
+					continue
+				}
+				g.Expect(c.Status).To(Equal(metav1.ConditionTrue), c.Type)
+			}
+			g.Expect(meta.FindStatusCondition(conditions, tt.msgCondition).Message).To(ContainSubstring(tt.msgContains))
+		})
+	}
+}
+
+func TestIsExternalFRRK8sAvailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		objects     []k8sclient.Object
+		expectErr   bool
+		errContains string
+	}{
+		{
+			name:    "ready",
+			objects: []k8sclient.Object{newFRRK8sCRD("frrconfigurations.frrk8s.metallb.io"), newFRRK8sCRD("frrnodestates.frrk8s.metallb.io"), newExternalFRRK8s()},
+		},
+		{
+			name:        "crd missing",
+			objects:     []k8sclient.Object{newFRRK8sCRD("frrconfigurations.frrk8s.metallb.io"), newExternalFRRK8s()},
+			expectErr:   true,
+			errContains: "frr-k8s crd frrnodestates.frrk8s.metallb.io not installed",
+		},
+		{
+			name:        "daemonset missing",
+			objects:     []k8sclient.Object{newFRRK8sCRD("frrconfigurations.frrk8s.metallb.io"), newFRRK8sCRD("frrnodestates.frrk8s.metallb.io")},
+			expectErr:   true,
+			errContains: "frr-k8s daemonset not found in namespace frr-k8s-system",
+		},
+		{
+			name: "daemonset not ready",
+			objects: func() []k8sclient.Object {
+				ds := newExternalFRRK8s()
+				ds.Status.NumberReady = 2
+				return []k8sclient.Object{newFRRK8sCRD("frrconfigurations.frrk8s.metallb.io"), newFRRK8sCRD("frrnodestates.frrk8s.metallb.io"), ds}
+			}(),
+			expectErr:   true,
+			errContains: "namespace frr-k8s-system: frr-k8s daemonset not ready, 2/3 pods ready",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(tt.objects...).Build()
+			condition, err := IsExternalFRRK8sAvailable(context.Background(), client, "frr-k8s-system")
+			g.Expect(condition.Type).To(Equal(ConditionFRRK8sReady))
+			if !tt.expectErr {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				return
+			}
+			g.Expect(err).To(BeAssignableToTypeOf(ExternalFRRK8sNotReadyError{}))
+			g.Expect(err.Error()).To(ContainSubstring(tt.errContains))
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(ReasonExternalFRRK8sNotReady))
+		})
+	}
+}
+
+func TestComponentConditions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deploy := newReadyController()
+	deploy.Status.ReadyReplicas = 0
+	client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(newReadySpeaker(), deploy).Build()
+
+	conditions, err := IsMetalLBAvailable(context.Background(), client, Components(renderedObjects()))
+	g.Expect(err).To(BeAssignableToTypeOf(MetalLBResourcesNotReadyError{}))
+	g.Expect(conditions).To(HaveLen(2))
+
+	speaker := meta.FindStatusCondition(conditions, ConditionSpeakerReady)
+	g.Expect(speaker).NotTo(BeNil())
+	g.Expect(speaker.Status).To(Equal(metav1.ConditionTrue))
+
+	controller := meta.FindStatusCondition(conditions, ConditionControllerReady)
+	g.Expect(controller).NotTo(BeNil())
+	g.Expect(controller.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(controller.Message).To(ContainSubstring("controller deployment not ready"))
+}
+
+func TestUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{Name: "metallb", Namespace: "test-ns", Generation: 3},
+		Status: metallbv1beta1.MetalLBStatus{
+			Conditions: []metav1.Condition{
+				{Type: ConditionFRRK8sReady, Status: metav1.ConditionTrue, Reason: reasonReady},
+				MonitoringUnavailable([]string{"ServiceMonitor"}),
+			},
+		},
+	}
+	controller := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"component": "controller"}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "controller", Image: "quay.io/metallb/controller@sha256:0123"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	controllerPods := []k8sclient.Object{}
+	for _, name := range []string{"controller-1", "controller-2"} {
+		controllerPods = append(controllerPods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: map[string]string{"component": "controller"}},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "controller", ImageID: "quay.io/metallb/controller@sha256:0123"}},
+			},
+		})
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(metallb, controller).WithObjects(controllerPods...).WithStatusSubresource(metallb).Build()
+
+	components := []metav1.Condition{
+		{Type: ConditionSpeakerReady, Status: metav1.ConditionTrue, Reason: reasonReady},
+		{Type: ConditionControllerReady, Status: metav1.ConditionTrue, Reason: reasonReady},
+	}
+	err := Update(context.Background(), client, metallb, &metallbv1beta1.EffectiveSpec{BGPBackend: metallbv1beta1.FRRK8sMode}, ConditionAvailable, ConditionAvailable, "", components...)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	updated := &metallbv1beta1.MetalLB{}
+	err = client.Get(context.Background(), k8sclient.ObjectKeyFromObject(metallb), updated)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Status.ObservedGeneration).To(Equal(int64(3)))
+	g.Expect(updated.Status.ControllerReadyReplicas).To(Equal(int32(2)))
+	g.Expect(updated.Status.EffectiveSpec).To(Equal(&metallbv1beta1.EffectiveSpec{BGPBackend: metallbv1beta1.FRRK8sMode}))
+	g.Expect(updated.Status.Images).To(Equal([]metallbv1beta1.OperandImage{
+		{
+			Workload:  "Deployment/controller",
+			Container: "controller",
+			Image:     "quay.io/metallb/controller@sha256:0123",
+			ImageIDs:  []string{"quay.io/metallb/controller@sha256:0123"},
+		},
+	}))
+	g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionAvailable)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionSpeakerReady)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionControllerReady)).To(BeTrue())
+	g.Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionFRRK8sReady)).To(BeNil())
+	g.Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionMonitoringUnavailable)).To(BeNil())
+	for _, c := range updated.Status.Conditions {
+		g.Expect(c.ObservedGeneration).To(Equal(int64(3)))
+	}
+
+	// A second update with the same conditions must not move the transition time.
+	available := meta.FindStatusCondition(updated.Status.Conditions, ConditionAvailable).LastTransitionTime
+	err = Update(context.Background(), client, updated, nil, ConditionAvailable, ConditionAvailable, "", components...)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionAvailable).LastTransitionTime).To(Equal(available))
+	g.Expect(updated.Status.EffectiveSpec).To(Equal(&metallbv1beta1.EffectiveSpec{BGPBackend: metallbv1beta1.FRRK8sMode}))
+}
+
+func TestUpdatePaused(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{Name: "metallb", Namespace: "test-ns", Generation: 2},
+		Status: metallbv1beta1.MetalLBStatus{
+			Conditions: []metav1.Condition{
+				{Type: ConditionAvailable, Status: metav1.ConditionTrue, Reason: ConditionAvailable},
+			},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(metallb).WithStatusSubresource(metallb).Build()
+
+	err := UpdatePaused(context.Background(), client, metallb, "paused")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	updated := &metallbv1beta1.MetalLB{}
+	err = client.Get(context.Background(), k8sclient.ObjectKeyFromObject(metallb), updated)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Status.ObservedGeneration).To(Equal(int64(2)))
+	g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionPaused)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionAvailable)).To(BeTrue())
+
+	// Resuming the reconciliation drops the Paused condition.
+	err = Update(context.Background(), client, updated, nil, ConditionAvailable, ConditionAvailable, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionPaused)).To(BeNil())
+}
+
+func TestUpdatePreview(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metallb := &metallbv1beta1.MetalLB{
+		ObjectMeta: metav1.ObjectMeta{Name: "metallb", Namespace: "test-ns", Generation: 2},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme()).WithObjects(metallb).WithStatusSubresource(metallb).Build()
+
+	changes := []metallbv1beta1.OperandChange{
+		{Kind: "DaemonSet", Namespace: "test-ns", Name: "speaker", Action: "Update", Fields: []string{"spec.template.spec"}},
+	}
+	err := UpdatePreview(context.Background(), client, metallb, changes)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	updated := &metallbv1beta1.MetalLB{}
+	err = client.Get(context.Background(), k8sclient.ObjectKeyFromObject(metallb), updated)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Status.PendingChanges).To(Equal(changes))
+	preview := meta.FindStatusCondition(updated.Status.Conditions, ConditionPreview)
+	g.Expect(preview).ToNot(BeNil())
+	g.Expect(preview.Reason).To(Equal(ReasonChangesPending))
+
+	// Applying the changes drops the pending ones.
+	err = Update(context.Background(), client, updated, nil, ConditionAvailable, ConditionAvailable, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated.Status.PendingChanges).To(BeEmpty())
+	g.Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionPreview)).To(BeNil())
+}
+
 func TestIsDaemonSetReady(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -227,9 +518,30 @@ func TestIsDeploymentReady(t *testing.T) {
 func scheme() *runtime.Scheme {
 	s := runtime.NewScheme()
 	_ = appsv1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	_ = discoveryv1.AddToScheme(s)
+	_ = apiextensionsv1.AddToScheme(s)
+	_ = metallbv1beta1.AddToScheme(s)
 	return s
 }
 
+func renderedObjects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		renderedObject("apps/v1", "DaemonSet", "speaker"),
+		renderedObject("apps/v1", "Deployment", "controller"),
+		renderedObject("v1", "ConfigMap", "config"),
+	}
+}
+
+func renderedObject(apiVersion, kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace("test-ns")
+	return obj
+}
+
 func validateConditionTypes(g *GomegaWithT, conditions []metav1.Condition) {
 	g.Expect(conditions[0].Type).To(Equal(ConditionAvailable))
 	g.Expect(conditions[1].Type).To(Equal(ConditionUpgradeable))
@@ -261,3 +573,45 @@ func newReadyController() *appsv1.Deployment {
 		},
 	}
 }
+
+func newReadyFRRK8s() *appsv1.DaemonSet {
+	ds := newReadySpeaker()
+	ds.Name = "frr-k8s"
+	return ds
+}
+
+func newReadyStatusCleaner() *appsv1.Deployment {
+	deploy := newReadyController()
+	deploy.Name = "statuscleaner"
+	return deploy
+}
+
+func newWebhookEndpoints(ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "frr-k8s-webhook-service-abcde",
+			Namespace: "test-ns",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "frr-k8s-webhook-service"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+			},
+		},
+	}
+}
+
+func newExternalFRRK8s() *appsv1.DaemonSet {
+	ds := newReadyFRRK8s()
+	ds.Namespace = "frr-k8s-system"
+	ds.Labels = map[string]string{"app.kubernetes.io/component": "frr-k8s"}
+	return ds
+}
+
+func newFRRK8sCRD(name string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+}
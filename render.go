@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	metallbv1beta1 "github.com/metallb/metallb-operator/api/v1beta1"
+	"github.com/metallb/metallb-operator/controllers"
+	"github.com/metallb/metallb-operator/pkg/params"
+)
+
+const renderCommand = "render"
+
+// render prints the manifests the operator would apply for the MetalLB
+// resource passed via the flags, using the same environment variables the
+// operator is configured with. It does not access the cluster, so the memberlist
+// key generated by the operator is replaced with a placeholder.
+func render(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet(renderCommand, flag.ExitOnError)
+	metallbFile := fs.String("metallb", "-", "The file containing the MetalLB resource, - to read it from the standard input")
+	isOpenshift := fs.Bool("openshift", false, "Render the manifests as they are applied on OpenShift")
+	metallbChart := fs.String("metallb-chart", controllers.MetalLBChartPath, "The path of the MetalLB helm chart")
+	frrk8sChart := fs.String("frrk8s-chart", controllers.FRRK8SChartPath, "The path of the frr-k8s helm chart")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *metallbFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*metallbFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the MetalLB resource: %w", err)
+	}
+	metallb := &metallbv1beta1.MetalLB{}
+	if err := yaml.UnmarshalStrict(data, metallb); err != nil {
+		return fmt.Errorf("failed to parse the MetalLB resource: %w", err)
+	}
+
+	envParams, err := params.FromEnvironment(*isOpenshift)
+	if err != nil {
+		return fmt.Errorf("failed to parse env params: %w", err)
+	}
+
+	controllers.MetalLBChartPath = *metallbChart
+	controllers.FRRK8SChartPath = *frrk8sChart
+	objs, err := controllers.Render(envParams, metallb)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests: %w", err)
+	}
+
+	for _, obj := range objs {
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}